@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ParseASCIIBoard reads a board drawn top row first:
+//
+//	.  empty
+//	*  food
+//	#  hazard
+//	A  the head of snake "A", its body drawn in lower case a
+//
+// Each snake's body is traced from its head through neighbouring cells of the
+// same letter, and every segment must have exactly one way on, so bodies can't
+// touch themselves. Every snake starts on full health.
+func ParseASCIIBoard(text string) (Board, error) {
+	rows := make([]string, 0)
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); len(line) > 0 {
+			rows = append(rows, line)
+		}
+	}
+	if len(rows) == 0 {
+		return Board{}, fmt.Errorf("empty board")
+	}
+
+	board := Board{Width: len(rows[0]), Height: len(rows), Food: make([]Coord, 0), Hazards: make([]Coord, 0), Snakes: make([]Battlesnake, 0)}
+	heads := make(map[rune]Coord)
+	bodies := make(map[rune]map[Coord]bool)
+	for row, line := range rows {
+		if len(line) != board.Width {
+			return Board{}, fmt.Errorf("row %d is %d wide, want %d", row+1, len(line), board.Width)
+		}
+		for x, ch := range line {
+			c := Coord{x, board.Height - 1 - row}
+			switch {
+			case ch == '.':
+			case ch == '*':
+				board.Food = append(board.Food, c)
+			case ch == '#':
+				board.Hazards = append(board.Hazards, c)
+			case ch < unicode.MaxASCII && unicode.IsUpper(ch):
+				if _, ok := heads[ch]; ok {
+					return Board{}, fmt.Errorf("snake %c has two heads", ch)
+				}
+				heads[ch] = c
+			case ch < unicode.MaxASCII && unicode.IsLower(ch):
+				id := unicode.ToUpper(ch)
+				if bodies[id] == nil {
+					bodies[id] = make(map[Coord]bool)
+				}
+				bodies[id][c] = true
+			default:
+				return Board{}, fmt.Errorf("unknown cell %q at %v", ch, c)
+			}
+		}
+	}
+
+	ids := make([]rune, 0, len(heads))
+	for id := range heads {
+		ids = append(ids, id)
+	}
+	for id := range bodies {
+		if _, ok := heads[id]; !ok {
+			return Board{}, fmt.Errorf("snake %c has no head", id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		body, err := traceBody(heads[id], bodies[id])
+		if err != nil {
+			return Board{}, fmt.Errorf("can't trace snake %c's body from its head, %w", id, err)
+		}
+		board.Snakes = append(board.Snakes, Battlesnake{
+			ID:     string(id),
+			Name:   string(id),
+			Health: 100,
+			Head:   heads[id],
+			Body:   body,
+			Length: len(body),
+		})
+	}
+	return board, nil
+}
+
+// most cells traceBody visits before giving up on a body, coils fork a lot
+// but resolve in a few steps, a solid block of body could take forever
+const maxTraceSteps = 100000
+
+// traceBody follows the body from the head through every cell, backing up
+// when a coiled body forks the wrong way
+func traceBody(head Coord, cells map[Coord]bool) ([]Coord, error) {
+	body := make([]Coord, 1, len(cells)+1)
+	body[0] = head
+	used := make(map[Coord]bool, len(cells))
+	steps := 0
+
+	var follow func() bool
+	follow = func() bool {
+		if len(body) == len(cells)+1 {
+			return true
+		}
+		curr := body[len(body)-1]
+		for _, c := range makeNextMoves(curr) {
+			if !cells[c] || used[c] || steps >= maxTraceSteps {
+				continue
+			}
+			steps++
+			used[c] = true
+			body = append(body, c)
+			if follow() {
+				return true
+			}
+			used[c] = false
+			body = body[:len(body)-1]
+		}
+		return false
+	}
+
+	if follow() {
+		return body, nil
+	}
+	if steps >= maxTraceSteps {
+		return nil, fmt.Errorf("gave up after %d steps, draw the body with fewer ways on", steps)
+	}
+	return nil, fmt.Errorf("no single path through all %d body cells", len(cells))
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BenchBaseline is the stored ns/op of each benchmark and the machine they
+// ran on. Timings only compare on the same machine, each keeps its own baseline.
+type BenchBaseline struct {
+	Machine string             `json:"machine"`
+	Results map[string]float64 `json:"results"`
+}
+
+// matches "BenchmarkFillMap/7x7/2snakes-8   	  20	  1419 ns/op"
+var benchLine = regexp.MustCompile(`^(Benchmark\S+?)(?:-\d+)?\s+\d+\s+([\d.]+) ns/op`)
+
+// matches the "goos: linux", "goarch: amd64" and "cpu: ..." header lines
+var benchMachineLine = regexp.MustCompile(`^(goos|goarch|cpu): (.+)$`)
+
+// benchCompare reads `go test -bench` output and checks it against the stored
+// baseline. It fails when a benchmark regresses past the tolerance, or any
+// mover takes longer than the latency budget to pick a move. A baseline from
+// another machine only checks the budget.
+func benchCompare(args []string) error {
+	flags := flag.NewFlagSet("bench-compare", flag.ContinueOnError)
+	baselinePath := flags.String("baseline", "benchmarks/baseline.json", "stored baseline results")
+	input := flags.String("input", "-", "go test -bench output, - for stdin")
+	budget := flags.Duration("budget", 100*time.Millisecond, "longest any mover may take to pick a move")
+	tolerance := flags.Float64("tolerance", 0.5, "allowed slowdown against the baseline, 0.5 is 50%")
+	update := flags.Bool("update", false, "write the results as the new baseline")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if *input != "-" {
+		f, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	results, err := parseBenchmarks(r)
+	if err != nil {
+		return err
+	}
+	if len(results.Results) == 0 {
+		return fmt.Errorf("no benchmark results found")
+	}
+
+	if *update {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Writing %d results from %s to %s\n", len(results.Results), results.Machine, *baselinePath)
+		return os.WriteFile(*baselinePath, append(data, '\n'), 0644)
+	}
+
+	baseline := BenchBaseline{}
+	data, err := os.ReadFile(*baselinePath)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return fmt.Errorf("parsing %s: %w", *baselinePath, err)
+	}
+	sameMachine := baseline.Machine == results.Machine
+	if !sameMachine {
+		fmt.Printf("Baseline is from %q, not this machine %q, only checking the budget. Run with -update to store a baseline here.\n", baseline.Machine, results.Machine)
+	}
+
+	names := make([]string, 0, len(results.Results))
+	for name := range results.Results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	failures := 0
+	for _, name := range names {
+		nsPerOp := results.Results[name]
+		status := "ok"
+		before, known := baseline.Results[name]
+		change := ""
+		if sameMachine && known && before > 0 {
+			change = fmt.Sprintf("%+.1f%%", (nsPerOp-before)/before*100)
+			if nsPerOp > before*(1+*tolerance) {
+				status = "SLOWER"
+				failures++
+			}
+		}
+		if strings.HasPrefix(name, "BenchmarkMovers/") && time.Duration(nsPerOp) > *budget {
+			status = "OVER BUDGET"
+			failures++
+		}
+		fmt.Printf("%-50s %14.0f ns/op %8s  %s\n", name, nsPerOp, change, status)
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d benchmarks failed", failures)
+	}
+	return nil
+}
+
+func parseBenchmarks(r io.Reader) (BenchBaseline, error) {
+	results := BenchBaseline{Results: make(map[string]float64)}
+	machine := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if match := benchMachineLine.FindStringSubmatch(scanner.Text()); match != nil {
+			machine[match[1]] = strings.TrimSpace(match[2])
+			continue
+		}
+		match := benchLine.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		nsPerOp, err := strconv.ParseFloat(match[2], 64)
+		if err != nil {
+			return BenchBaseline{}, fmt.Errorf("parsing %q: %w", scanner.Text(), err)
+		}
+		results.Results[match[1]] = nsPerOp
+	}
+	results.Machine = fmt.Sprintf("%s/%s %s", machine["goos"], machine["goarch"], machine["cpu"])
+	return results, scanner.Err()
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+var benchSizes = []struct{ width, height int }{{7, 7}, {11, 11}, {19, 19}}
+var benchSnakeCounts = []int{2, 4, 8}
+
+// syntheticState is a mid-game board, snakes wander out from a random start
+func syntheticState(width, height, snakes int, seed int64) GameState {
+	state := GenerateState(BoardSpec{
+		Width:       width,
+		Height:      height,
+		Snakes:      snakes,
+		MinLength:   3,
+		MaxLength:   2 + width/2,
+		MinHealth:   30,
+		MaxHealth:   100,
+		FoodDensity: 0.05,
+		Turn:        50,
+	}, rand.New(rand.NewSource(seed)))
+	state.Game.ID = fmt.Sprintf("bench-%d", seed)
+	return state
+}
+
+// benchBoards runs fn over every synthetic board size and snake count
+func benchBoards(b *testing.B, fn func(b *testing.B, state GameState)) {
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	for _, size := range benchSizes {
+		for _, count := range benchSnakeCounts {
+			state := syntheticState(size.width, size.height, count, int64(size.width*100+count))
+			b.Run(fmt.Sprintf("%dx%d/%dsnakes", size.width, size.height, count), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					fn(b, state)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkFillToDepth(b *testing.B) {
+	benchBoards(b, func(b *testing.B, state GameState) {
+		fillToDepth(state.You.Head, state.You.Length, state.Board)
+	})
+}
+
+func BenchmarkMakeHeadZones(b *testing.B) {
+	benchBoards(b, func(b *testing.B, state GameState) {
+		MakeHeadZones(FindOtherSnakes(opponents(state), state.Board), state.You, state.Board, 2)
+	})
+}
+
+func BenchmarkFillMap(b *testing.B) {
+	benchBoards(b, func(b *testing.B, state GameState) {
+		fillMap(state.Board, state.You)
+	})
+}
+
+func BenchmarkSaferMoves(b *testing.B) {
+	benchBoards(b, func(b *testing.B, state GameState) {
+		saferMoves(state.You.Head, makeTimedMap(state.Board), make([]Coord, 0), 4, headZonesFor(state, 1))
+	})
+}
+
+func BenchmarkNearestFoods(b *testing.B) {
+	benchBoards(b, func(b *testing.B, state GameState) {
+		NearestFoods(state.You, state.Board, state.Game.Ruleset.Settings.HazardDamagePerTurn)
+	})
+}
+
+// BenchmarkMovers plays every iteration as its own game and ends it, so
+// nothing a mover keeps per game carries over between iterations
+func BenchmarkMovers(b *testing.B) {
+	for _, m := range registeredMovers() {
+		m := m
+		b.Run(m.Name, func(b *testing.B) {
+			games := 0
+			benchBoards(b, func(b *testing.B, state GameState) {
+				games++
+				state.Game.ID = fmt.Sprintf("%s-%d", state.Game.ID, games)
+				m.Mover(state)
+				b.StopTimer()
+				end(state)
+				b.StartTimer()
+			})
+		})
+	}
+}
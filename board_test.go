@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+var rectangularBoards = []struct{ width, height int }{{7, 11}, {19, 7}}
+
+func TestRectangularBoardHelpers(t *testing.T) {
+	for _, size := range rectangularBoards {
+		board := Board{Width: size.width, Height: size.height}
+		top, right := size.height-1, size.width-1
+		corners := []Coord{{0, 0}, {0, top}, {right, 0}, {right, top}}
+
+		for _, c := range corners {
+			if !isCorner(c, board) {
+				t.Errorf("%dx%d: %v should be a corner", size.width, size.height, c)
+			}
+			if !isOnBorder(c, board) || isOffBoard(c, board) {
+				t.Errorf("%dx%d: %v should be on the border", size.width, size.height, c)
+			}
+			if got := nearestCorner(Coord{c.X + (right/2-c.X)/2, c.Y + (top/2-c.Y)/2}, board); got != c {
+				t.Errorf("%dx%d: nearest corner toward %v was %v", size.width, size.height, c, got)
+			}
+		}
+		if isCorner(Coord{right, right}, board) {
+			t.Errorf("%dx%d: (%d,%d) isn't a corner", size.width, size.height, right, right)
+		}
+		for _, c := range []Coord{{-1, 0}, {0, size.height}, {size.width, 0}} {
+			if !isOffBoard(c, board) {
+				t.Errorf("%dx%d: %v should be off the board", size.width, size.height, c)
+			}
+		}
+
+		gameMap := fillMap(board, Battlesnake{})
+		if len(gameMap) != size.width || len(gameMap[0]) != size.height {
+			t.Errorf("%dx%d: game map is %dx%d", size.width, size.height, len(gameMap), len(gameMap[0]))
+		}
+		if ok, _ := cell(right, top, gameMap); !ok {
+			t.Errorf("%dx%d: top right cell missing from the game map", size.width, size.height)
+		}
+		if ok, _ := cell(right, size.height, gameMap); ok {
+			t.Errorf("%dx%d: game map reaches past the top", size.width, size.height)
+		}
+
+		for _, c := range corners {
+			state := GameState{Board: board}
+			score := centerFeature(state, WeightedMovement{root: c})
+			if score < 0 || score > 1 {
+				t.Errorf("%dx%d: center score %.2f at %v is outside 0..1", size.width, size.height, score, c)
+			}
+		}
+	}
+}
+
+func TestRectangularBoardSearch(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	for _, size := range rectangularBoards {
+		board := Board{Width: size.width, Height: size.height}
+		cells := size.width * size.height
+
+		// heading into the top right corner
+		you := Battlesnake{ID: "you", Name: "you", Health: 100, Head: Coord{size.width - 2, size.height - 1}, Body: []Coord{{size.width - 2, size.height - 1}, {size.width - 3, size.height - 1}, {size.width - 4, size.height - 1}}, Length: 3}
+		board.Snakes = []Battlesnake{you}
+		for _, m := range fillToDepth(you.Head, you.Length, board) {
+			if m.movement == Right && !m.movingToCorner {
+				t.Errorf("%dx%d: moving right into the top right corner isn't flagged", size.width, size.height)
+			}
+			if m.movement == Down && m.movingToCorner {
+				t.Errorf("%dx%d: moving down is flagged as a corner", size.width, size.height)
+			}
+		}
+
+		if got := len(reachableArea(you.Head, board, -1)); got != cells-1 {
+			t.Errorf("%dx%d: reached %d cells, want %d", size.width, size.height, got, cells-1)
+		}
+		// alone on the board, every cell is ours in the end
+		territory := VoronoiTerritory(board)
+		if got := territory.Of("you").Cells; got != cells {
+			t.Errorf("%dx%d: territory %d cells, want %d", size.width, size.height, got, cells)
+		}
+		chambers := FindChambers(board)
+		total := len(chambers.Chokepoints)
+		for _, c := range chambers.Chambers {
+			total += len(c.Cells)
+		}
+		// the tail is gone next turn
+		if free := cells - len(you.Body) + 1; total != free {
+			t.Errorf("%dx%d: chambers hold %d cells, want %d", size.width, size.height, total, free)
+		}
+	}
+}
+
+func TestRectangularBoardMovers(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	for _, size := range rectangularBoards {
+		for seed := int64(0); seed < 20; seed++ {
+			state := GenerateState(BoardSpec{Width: size.width, Height: size.height, Snakes: 4, MinLength: 3, MaxLength: 8, FoodDensity: 0.05}, rand.New(rand.NewSource(seed)))
+			moves := fillToDepthWithin(context.Background(), state.You.Head, state.You.Length, state.Board)
+			survivable := false
+			for _, m := range moves {
+				if !fatalMove(state, m.movement.asString()) {
+					survivable = true
+				}
+			}
+			for _, m := range registeredMovers() {
+				move := m.Mover(state).Move
+				if survivable && fatalMove(state, move) {
+					t.Errorf("%dx%d seed %d: %s chose fatal %s", size.width, size.height, seed, m.Name, move)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"fmt"
+	"log"
+)
+
+// Chamber is a run of free cells with no chokepoint inside it
+type Chamber struct {
+	ID    int
+	Cells []Coord
+	// chokepoints leading out of the chamber
+	Doors []Coord
+	Food  []Coord
+	// snakes with a head next to the chamber
+	Snakes []string
+}
+
+type ChamberMap struct {
+	Chambers []Chamber
+	// chamber ID of each free cell, chokepoints are left out
+	ChamberOf   map[Coord]int
+	Chokepoints []Coord
+}
+
+// ChamberEntry is the room we end up in after a move
+type ChamberEntry struct {
+	Movement     Movement
+	Root         Coord
+	ChamberID    int
+	AtChokepoint bool
+	Size         int
+	// ways out of the chamber, a chokepoint counts everything beyond it in Size
+	Doors int
+	// cells reachable from the move through any door, the chamber included
+	Reachable int
+	Food      int
+	Opponents []string
+}
+
+// FindChambers splits the cells free next turn into chambers joined by
+// chokepoints, the articulation points of the free cell graph
+func FindChambers(board Board) ChamberMap {
+	timed := makeTimedMap(board)
+	free := func(c Coord) bool { return timed.freeAt(c, 1) }
+
+	chokepoints := articulationPoints(board, free)
+	isDoor := make(map[Coord]bool, len(chokepoints))
+	for _, c := range chokepoints {
+		isDoor[c] = true
+	}
+
+	chambers := ChamberMap{ChamberOf: make(map[Coord]int), Chokepoints: chokepoints}
+	for x := 0; x < board.Width; x++ {
+		for y := 0; y < board.Height; y++ {
+			start := Coord{x, y}
+			if !free(start) || isDoor[start] {
+				continue
+			}
+			if _, seen := chambers.ChamberOf[start]; seen {
+				continue
+			}
+			id := len(chambers.Chambers)
+			room := flood(start, func(c Coord) bool { return free(c) && !isDoor[c] })
+			chamber := Chamber{ID: id, Cells: room, Doors: make([]Coord, 0), Food: make([]Coord, 0), Snakes: make([]string, 0)}
+			for _, c := range room {
+				chambers.ChamberOf[c] = id
+				if hasFood(c, board.Food) {
+					chamber.Food = append(chamber.Food, c)
+				}
+				for _, n := range makeNextMoves(c) {
+					if isDoor[n] && !hasCoord(n, chamber.Doors) {
+						chamber.Doors = append(chamber.Doors, n)
+					}
+				}
+			}
+			chamber.Snakes = snakesBordering(room, board)
+			chambers.Chambers = append(chambers.Chambers, chamber)
+		}
+	}
+	return chambers
+}
+
+// ChambersForMoves reports the chamber each move leads into. A move onto a
+// chokepoint leads into the biggest room on the far side of it.
+func ChambersForMoves(state GameState, moves WeightedMovementSet) []ChamberEntry {
+	chambers := FindChambers(state.Board)
+	timed := makeTimedMap(state.Board)
+	entries := make([]ChamberEntry, 0, len(moves))
+
+	for _, m := range moves {
+		entry := ChamberEntry{Movement: m.movement, Root: m.root, ChamberID: -1, Opponents: make([]string, 0)}
+		entry.Reachable = len(flood(m.root, func(c Coord) bool { return timed.freeAt(c, 1) }))
+		if id, ok := chambers.ChamberOf[m.root]; ok {
+			chamber := chambers.Chambers[id]
+			entry.ChamberID = id
+			entry.Size = len(chamber.Cells)
+			entry.Doors = len(chamber.Doors)
+			entry.Food = len(chamber.Food)
+			entry.Opponents = without(chamber.Snakes, state.You.Name)
+		} else if timed.freeAt(m.root, 1) {
+			entry.AtChokepoint = true
+			var room []Coord
+			for _, n := range makeNextMoves(m.root) {
+				if !timed.freeAt(n, 1) {
+					continue
+				}
+				side := flood(n, func(c Coord) bool { return c != m.root && timed.freeAt(c, 1) })
+				if len(side) > len(room) {
+					room = side
+				}
+			}
+			entry.Size = len(room)
+			for _, f := range state.Board.Food {
+				if hasCoord(f, room) {
+					entry.Food++
+				}
+			}
+			entry.Opponents = without(snakesBordering(room, state.Board), state.You.Name)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func (e ChamberEntry) String() string {
+	return fmt.Sprintf("%s: chamber %d size %d, doors %d to %d cells, food %d, chokepoint %v, shared with %v",
+		e.Movement.asString(), e.ChamberID, e.Size, e.Doors, e.Reachable, e.Food, e.AtChokepoint, e.Opponents)
+}
+
+// articulationPoints finds the free cells whose loss splits the free cells
+// around them apart, using Tarjan's low-link search
+func articulationPoints(board Board, free func(Coord) bool) []Coord {
+	order := make(map[Coord]int)
+	low := make(map[Coord]int)
+	points := make([]Coord, 0)
+	isPoint := make(map[Coord]bool)
+	counter := 0
+
+	var visit func(c, parent Coord, root bool)
+	visit = func(c, parent Coord, root bool) {
+		counter++
+		order[c] = counter
+		low[c] = counter
+		children := 0
+		for _, n := range makeNextMoves(c) {
+			if !free(n) {
+				continue
+			}
+			if _, seen := order[n]; !seen {
+				children++
+				visit(n, c, false)
+				if low[n] < low[c] {
+					low[c] = low[n]
+				}
+				if !root && low[n] >= order[c] && !isPoint[c] {
+					isPoint[c] = true
+					points = append(points, c)
+				}
+			} else if n != parent && order[n] < low[c] {
+				low[c] = order[n]
+			}
+		}
+		if root && children > 1 && !isPoint[c] {
+			isPoint[c] = true
+			points = append(points, c)
+		}
+	}
+
+	for x := 0; x < board.Width; x++ {
+		for y := 0; y < board.Height; y++ {
+			c := Coord{x, y}
+			if _, seen := order[c]; !seen && free(c) {
+				visit(c, c, true)
+			}
+		}
+	}
+	return points
+}
+
+// flood returns every cell connected to start through cells passing open
+func flood(start Coord, open func(Coord) bool) []Coord {
+	if !open(start) {
+		return []Coord{}
+	}
+	seen := map[Coord]bool{start: true}
+	cells := []Coord{start}
+	q := Queue{}
+	q.Enqueue(start)
+	for !q.IsEmpty() {
+		curr, _ := q.Dequeue()
+		for _, n := range makeNextMoves(curr) {
+			if !seen[n] && open(n) {
+				seen[n] = true
+				cells = append(cells, n)
+				q.Enqueue(n)
+			}
+		}
+	}
+	return cells
+}
+
+func snakesBordering(cells []Coord, board Board) []string {
+	names := make([]string, 0)
+	for _, s := range board.Snakes {
+		for _, n := range makeNextMoves(s.Head) {
+			if hasCoord(n, cells) {
+				names = append(names, s.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+func without(names []string, name string) []string {
+	others := make([]string, 0, len(names))
+	for _, n := range names {
+		if n != name {
+			others = append(others, n)
+		}
+	}
+	return others
+}
+
+// chamberFeature is the share of the board in the room the move leads into,
+// and counts hard against rooms too small to hold us
+func chamberFeature(state GameState, move WeightedMovement) float64 {
+	cells := state.Board.Width * state.Board.Height
+	if cells == 0 {
+		return 0
+	}
+	entry := ChambersForMoves(state, WeightedMovementSet{move})[0]
+	if entry.Size < state.You.Length {
+		return -1
+	}
+	return float64(entry.Size) / float64(cells)
+}
+
+// avoidSmallChambers drops moves into rooms too small to hold us, unless their
+// doors lead on to enough room, or that would leave nothing
+func (moves WeightedMovementSet) avoidSmallChambers(state GameState) WeightedMovementSet {
+	roomy := make(WeightedMovementSet, 0, len(moves))
+	for i, entry := range ChambersForMoves(state, moves) {
+		log.Printf("[%s] Chamber %s", state.You.Name, entry)
+		if entry.Reachable >= state.You.Length {
+			roomy = append(roomy, moves[i])
+		}
+	}
+	if len(roomy) == 0 {
+		return moves
+	}
+	return roomy
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+// gridFree reads '#' as blocked, with the top row first like ParseASCIIBoard
+func gridFree(ascii string) (Board, func(Coord) bool) {
+	rows := strings.Fields(ascii)
+	board := Board{Width: len(rows[0]), Height: len(rows)}
+	return board, func(c Coord) bool {
+		if isOffBoard(c, board) {
+			return false
+		}
+		return rows[board.Height-1-c.Y][c.X] != '#'
+	}
+}
+
+func sortCoords(coords []Coord) []Coord {
+	sort.Slice(coords, func(i, j int) bool {
+		if coords[i].X == coords[j].X {
+			return coords[i].Y < coords[j].Y
+		}
+		return coords[i].X < coords[j].X
+	})
+	return coords
+}
+
+func TestArticulationPoints(t *testing.T) {
+	tests := []struct {
+		name  string
+		ascii string
+		want  []Coord
+	}{
+		{"open", `
+			...
+			...
+		`, []Coord{}},
+		{"corridor", `
+			..#..
+			.....
+			..#..
+		`, []Coord{{1, 1}, {2, 1}, {3, 1}}},
+		{"pocket", `
+			.#...
+			.#...
+			.....
+		`, []Coord{{0, 0}, {0, 1}, {1, 0}, {2, 0}}},
+	}
+	for _, tt := range tests {
+		board, free := gridFree(tt.ascii)
+		got := sortCoords(articulationPoints(board, free))
+		if !sameCoords(got, tt.want) || len(got) != len(tt.want) {
+			t.Errorf("%s: articulation points %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFindChambers(t *testing.T) {
+	// B walls the board in two, its tail moves off the only way across
+	board, err := ParseASCIIBoard(`
+		..B..
+		..b..
+		..b..
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chambers := FindChambers(board)
+	want := []Coord{{1, 0}, {2, 0}, {3, 0}}
+	if got := sortCoords(chambers.Chokepoints); !sameCoords(got, want) || len(got) != len(want) {
+		t.Errorf("chokepoints %v, want %v", got, want)
+	}
+	if len(chambers.Chambers) != 2 {
+		t.Fatalf("got %d chambers, want 2", len(chambers.Chambers))
+	}
+	for _, c := range chambers.Chambers {
+		if len(c.Cells) != 5 || len(c.Doors) != 1 {
+			t.Errorf("chamber %d has %d cells and doors %v, want 5 cells behind one door", c.ID, len(c.Cells), c.Doors)
+		}
+	}
+	if _, ok := chambers.ChamberOf[Coord{2, 0}]; ok {
+		t.Errorf("the chokepoint was put in a chamber")
+	}
+}
+
+func TestAvoidSmallChambersCountsPastDoors(t *testing.T) {
+	// left leads into a dead end column of 4, split by chokepoints, too short for us
+	board, err := ParseASCIIBoard(`
+		.A...
+		.a...
+		.a...
+		.aa..
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	you, _ := findSnake("A", board.Snakes)
+	state := GameState{Game: Game{ID: t.Name()}, Board: board, You: you}
+	moves := fillToDepth(you.Head, you.Length, board).avoidCertainDeath()
+
+	entries := ChambersForMoves(state, moves)
+	for _, e := range entries {
+		if e.Movement == Left && (e.Doors == 0 || e.Reachable != 4) {
+			t.Errorf("left = %s, want a door to 4 cells", e)
+		}
+	}
+	roomy := moves.avoidSmallChambers(state)
+	if len(roomy) != 1 || roomy[0].movement != Right {
+		t.Errorf("kept %v, want only right", roomy)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// commands run offline tooling from the same binary as the server, e.g.
+//
+//	go test -run xxx -bench . | go run . bench-compare
+var commands = map[string]func(args []string) error{
+	"bench-compare": benchCompare,
+	"disagree":      disagree,
+	"golden":        golden,
+	"tune":          tune,
+}
+
+// runCommand runs the named command and exits, non-zero on failure
+func runCommand(name string, args []string) {
+	command, ok := commands[name]
+	if !ok {
+		names := make([]string, 0, len(commands))
+		for n := range commands {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(os.Stderr, "unknown command %q, expected one of %v\n", name, names)
+		os.Exit(2)
+	}
+	if err := command(args); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
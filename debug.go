@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync/atomic"
+)
+
+// DebugRequest is a GameState, or an ASCII board with the letter of our snake
+type DebugRequest struct {
+	GameState
+	ASCII string `json:"ascii"`
+	// our snake on the ASCII board, A by default
+	Snake string `json:"snake"`
+	// health of snakes on the ASCII board, by letter
+	Health map[string]int `json:"health"`
+}
+
+// DebugEvaluation is what every mover makes of a position
+type DebugEvaluation struct {
+	Movers []DebugMove `json:"movers"`
+}
+
+// DebugMove is one mover's choice and how it got there
+type DebugMove struct {
+	Name  string `json:"name"`
+	Move  string `json:"move"`
+	Shout string `json:"shout,omitempty"`
+	// every direction as the mover scored it, up, down, left, right
+	Directions []TraceCandidate `json:"directions"`
+	Trace      *Trace           `json:"trace,omitempty"`
+}
+
+// DEBUG_ENDPOINT=1 serves /debug/evaluate, it runs every mover for anyone who asks
+var debugEndpointEnabled = os.Getenv("DEBUG_ENDPOINT") == "1" || os.Getenv("DEBUG_ENDPOINT") == "true"
+
+// the largest board the endpoint will evaluate
+const maxDebugBoardSize = 25
+
+// every evaluation plays as its own game, so nothing a real game keeps is touched
+var debugGames int64
+
+// HandleDebugEvaluate asks every registered mover what it would do in the posted position
+func HandleDebugEvaluate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST a GameState or an ASCII board", http.StatusMethodNotAllowed)
+		return
+	}
+	request := DebugRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, fmt.Sprintf("decoding request: %s", err), http.StatusBadRequest)
+		return
+	}
+	state, err := request.state()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	evaluation := evaluatePosition(state)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(evaluation); err != nil {
+		log.Printf("ERROR: Failed to encode debug evaluation, %s", err)
+	}
+}
+
+// state builds the position to evaluate, as its own debug game
+func (request DebugRequest) state() (GameState, error) {
+	state := request.GameState
+	if len(request.ASCII) > 0 {
+		board, err := ParseASCIIBoard(request.ASCII)
+		if err != nil {
+			return GameState{}, err
+		}
+		snake := request.Snake
+		if len(snake) == 0 {
+			snake = "A"
+		}
+		for i := range board.Snakes {
+			if health, ok := request.Health[board.Snakes[i].ID]; ok {
+				board.Snakes[i].Health = health
+			}
+		}
+		you, ok := findSnake(snake, board.Snakes)
+		if !ok {
+			return GameState{}, fmt.Errorf("snake %s isn't on the board", snake)
+		}
+		state.Board = board
+		state.You = you
+	}
+	if len(state.You.Body) == 0 {
+		return GameState{}, fmt.Errorf("no snake to move")
+	}
+	if state.Board.Width > maxDebugBoardSize || state.Board.Height > maxDebugBoardSize {
+		return GameState{}, fmt.Errorf("board is %dx%d, the most is %dx%d", state.Board.Width, state.Board.Height, maxDebugBoardSize, maxDebugBoardSize)
+	}
+	if err := checkState(state); err != nil {
+		return GameState{}, err
+	}
+	if state.Game.Timeout <= 0 {
+		state.Game.Timeout = int(defaultMoveTimeout.Milliseconds())
+	}
+	state.Game.ID = fmt.Sprintf("debug-%d/%s", atomic.AddInt64(&debugGames, 1), state.Game.ID)
+	return state, nil
+}
+
+// evaluatePosition runs every mover, then forgets the debug game
+func evaluatePosition(state GameState) DebugEvaluation {
+	defer stopPondering(state)
+	defer forgetTailChase(state)
+
+	evaluation := DebugEvaluation{Movers: make([]DebugMove, 0)}
+	for _, m := range registeredMovers() {
+		response := m.Mover(state)
+		evaluation.Movers = append(evaluation.Movers, DebugMove{
+			Name:       m.Name,
+			Move:       response.Move,
+			Shout:      response.Shout,
+			Directions: traceDirections(response.Trace),
+			Trace:      response.Trace,
+		})
+	}
+	return evaluation
+}
+
+// traceDirections lists every direction from the trace's candidates, those
+// the mover never looked at are dropped as not considered
+func traceDirections(trace *Trace) []TraceCandidate {
+	directions := make([]TraceCandidate, 0, 4)
+	for _, move := range []string{"up", "down", "left", "right"} {
+		d := TraceCandidate{Move: move, Dropped: "not considered"}
+		if trace != nil {
+			for _, c := range trace.Candidates {
+				if c.Move == move {
+					d = c
+				}
+			}
+		}
+		directions = append(directions, d)
+	}
+	return directions
+}
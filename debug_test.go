@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseASCIIBoard(t *testing.T) {
+	board, err := ParseASCIIBoard(`
+		.....
+		.aaA.
+		.a.*.
+		.a.B#
+		...b.
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if board.Width != 5 || board.Height != 5 {
+		t.Fatalf("board is %dx%d, want 5x5", board.Width, board.Height)
+	}
+	a, _ := findSnake("A", board.Snakes)
+	want := []Coord{{3, 3}, {2, 3}, {1, 3}, {1, 2}, {1, 1}}
+	if !sameCoords(a.Body, want) || a.Body[len(a.Body)-1] != (Coord{1, 1}) || a.Length != 5 {
+		t.Errorf("A = %v, want %v", a.Body, want)
+	}
+	b, _ := findSnake("B", board.Snakes)
+	if b.Head != (Coord{3, 1}) || b.Length != 2 {
+		t.Errorf("B = %v", b.Body)
+	}
+	if !hasCoord(Coord{3, 2}, board.Food) || !hasCoord(Coord{4, 1}, board.Hazards) {
+		t.Errorf("food %v, hazards %v", board.Food, board.Hazards)
+	}
+
+	for _, bad := range []string{"..\n...", ".a.\n...", "A?.\n..."} {
+		if _, err := ParseASCIIBoard(bad); err == nil {
+			t.Errorf("expected an error parsing %q", bad)
+		}
+	}
+}
+
+func TestParseASCIIBoardCoiledBody(t *testing.T) {
+	board, err := ParseASCIIBoard(`
+		.....
+		Aaaa.
+		aaaa.
+		.....
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := findSnake("A", board.Snakes)
+	if a.Length != 8 {
+		t.Fatalf("A = %v, want all 8 cells", a.Body)
+	}
+	for i := 1; i < len(a.Body); i++ {
+		if distanceTo(a.Body[i-1], a.Body[i]) != 1 {
+			t.Errorf("A = %v, segments %d and %d aren't neighbours", a.Body, i-1, i)
+		}
+	}
+}
+
+func TestParseASCIIBoardLargeBody(t *testing.T) {
+	// a block of body with one cell missing can't be walked end to end,
+	// with a fork at every segment it must still fail fast
+	rows := []string{"A......"}
+	for i := 0; i < 6; i++ {
+		rows = append(rows, "aaaaaaa")
+	}
+	rows[5] = "aaaaaa."
+	start := time.Now()
+	if _, err := ParseASCIIBoard(strings.Join(rows, "\n")); err == nil {
+		t.Errorf("expected an error tracing a block of body")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %s to reject a block of body", elapsed)
+	}
+}
+
+func TestDebugEvaluate(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	body := `{"game": {"id": "real"}, "ascii": "....\n.Aa.\n..a.\n.B..", "health": {"A": 40}}`
+	w := httptest.NewRecorder()
+	HandleDebugEvaluate(w, httptest.NewRequest(http.MethodPost, "/debug/evaluate", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+
+	var evaluation DebugEvaluation
+	if err := json.NewDecoder(w.Body).Decode(&evaluation); err != nil {
+		t.Fatal(err)
+	}
+	if len(evaluation.Movers) != len(registeredMovers()) {
+		t.Errorf("got %d movers, want %d", len(evaluation.Movers), len(registeredMovers()))
+	}
+	for _, m := range evaluation.Movers {
+		if m.Move == "right" {
+			t.Errorf("%s moved into its own body", m.Name)
+		}
+		if m.Trace == nil {
+			t.Errorf("%s left no trace", m.Name)
+		}
+		if len(m.Directions) != 4 {
+			t.Errorf("%s scored %d directions, want 4", m.Name, len(m.Directions))
+		}
+		for _, d := range m.Directions {
+			if d.Move == m.Move && len(d.Dropped) > 0 {
+				t.Errorf("%s moved %s but dropped it, %s", m.Name, d.Move, d.Dropped)
+			}
+		}
+	}
+
+	w = httptest.NewRecorder()
+	HandleDebugEvaluate(w, httptest.NewRequest(http.MethodPost, "/debug/evaluate", strings.NewReader(`{"ascii": "a.."}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("a headless snake should be a bad request, got %d", w.Code)
+	}
+}
+
+func TestDebugEvaluateKeepsGamesApart(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	// boxed in on health between the tail chase thresholds, so whether we
+	// chase depends on whether this game was already chasing
+	ascii := ".....\n.Aaa.\n...a.\n.aaa.\n....."
+	board, err := ParseASCIIBoard(ascii)
+	if err != nil {
+		t.Fatal(err)
+	}
+	board.Snakes[0].Health = 55
+	real := GameState{Game: Game{ID: "real", Timeout: 500}, Turn: 150, Board: board, You: board.Snakes[0]}
+	defer forgetTailChase(real)
+
+	if moveSmart(real).Trace.DecidedBy == "tail chase" {
+		t.Fatalf("a game that wasn't chasing its tail shouldn't start on %d health", real.You.Health)
+	}
+	tailChasing.mu.Lock()
+	tailChasing.chasing[gameKey(real)] = true
+	tailChasing.mu.Unlock()
+	if decided := moveSmart(real).Trace.DecidedBy; decided != "tail chase" {
+		t.Fatalf("a game already chasing its tail should keep on, decided by %q", decided)
+	}
+
+	body, err := json.Marshal(DebugRequest{GameState: GameState{Game: Game{ID: "real"}, Turn: 150}, ASCII: ascii, Health: map[string]int{"A": 55}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	HandleDebugEvaluate(w, httptest.NewRequest(http.MethodPost, "/debug/evaluate", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var evaluation DebugEvaluation
+	if err := json.NewDecoder(w.Body).Decode(&evaluation); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range evaluation.Movers {
+		if m.Name == "smart" && m.Trace.DecidedBy == "tail chase" {
+			t.Errorf("the debug game picked up the real game's tail chase")
+		}
+	}
+
+	tailChasing.mu.Lock()
+	defer tailChasing.mu.Unlock()
+	if !tailChasing.chasing[gameKey(real)] {
+		t.Errorf("the real game's tail chase was forgotten")
+	}
+}
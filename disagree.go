@@ -0,0 +1,286 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// loggedGame is every position one of our snakes saw in a game, in turn order
+type loggedGame struct {
+	key    string
+	states []GameState
+	// the move we answered from each state, "" where it wasn't logged
+	moves []string
+	// the turn we were eliminated on, -1 if we weren't
+	diedOn int
+}
+
+// played is the move we made from the position at index i, "" if the log doesn't show it
+func (g loggedGame) played(i int) string {
+	if len(g.moves[i]) > 0 {
+		return g.moves[i]
+	}
+	// logs from before moves were recorded, work it out from where our head went
+	if i+1 >= len(g.states) || g.states[i+1].Turn != g.states[i].Turn+1 {
+		return ""
+	}
+	from, to := g.states[i].You.Head, g.states[i+1].You.Head
+	if distanceTo(from, to) != 1 {
+		return ""
+	}
+	return dir(from, to)
+}
+
+// ledToDeath is true if we played a move from the position at index i and
+// were eliminated within horizon turns of it
+func (g loggedGame) ledToDeath(i, horizon int) bool {
+	return g.diedOn >= 0 && len(g.played(i)) > 0 && g.diedOn-g.states[i].Turn <= horizon
+}
+
+// disagreementTally counts positions and disagreements in one bucket
+type disagreementTally struct {
+	positions     int
+	disagreements int
+}
+
+func (t *disagreementTally) add(disagree bool) {
+	t.positions++
+	if disagree {
+		t.disagreements++
+	}
+}
+
+func (t disagreementTally) String() string {
+	rate := 0.0
+	if t.positions > 0 {
+		rate = float64(t.disagreements) / float64(t.positions) * 100
+	}
+	return fmt.Sprintf("%6d positions %6d disagree %5.1f%%", t.positions, t.disagreements, rate)
+}
+
+// fatalPosition is a position where the move we played died soon after
+type fatalPosition struct {
+	game   string
+	turn   int
+	diedOn int
+	played string
+	moves  map[string]string
+}
+
+// disagree replays a corpus of logged positions through every mover and
+// reports where they choose differently, broken down by game phase, snake
+// count and health. Positions where the move we played led to our death are
+// listed with what each mover would have done instead.
+//
+//	go run . disagree -input games.jsonl
+func disagree(args []string) error {
+	flags := flag.NewFlagSet("disagree", flag.ContinueOnError)
+	input := flags.String("input", "-", "JSONL of GameStates, - for stdin")
+	horizon := flags.Int("horizon", 3, "a move led to death if we died within this many turns")
+	verbose := flags.Bool("verbose", false, "keep the snakes' move logs")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if !*verbose {
+		log.SetOutput(io.Discard)
+		defer log.SetOutput(os.Stderr)
+	}
+
+	var r io.Reader = os.Stdin
+	if *input != "-" {
+		f, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	games, err := readLoggedGames(r)
+	if err != nil {
+		return err
+	}
+
+	movers := registeredMovers()
+	total := disagreementTally{}
+	buckets := map[string]map[string]*disagreementTally{"phase": {}, "snakes": {}, "health": {}}
+	pairs := make(map[string]*disagreementTally)
+	fatal := make([]fatalPosition, 0)
+
+	for _, g := range games {
+		for i, logged := range g.states {
+			if !hasSnake(logged.You.ID, logged.Board.Snakes) {
+				// the end of the game, nothing to move
+				continue
+			}
+			state := logged
+			state.Game.ID = "disagree/" + logged.Game.ID
+			observeOpponents(state)
+
+			moves := make(map[string]string, len(movers))
+			distinct := make(map[string]bool)
+			for _, m := range movers {
+				moves[m.Name] = m.Mover(state).Move
+				distinct[moves[m.Name]] = true
+			}
+			disagreed := len(distinct) > 1
+
+			total.add(disagreed)
+			for kind, bucket := range situationBuckets(logged) {
+				if buckets[kind][bucket] == nil {
+					buckets[kind][bucket] = &disagreementTally{}
+				}
+				buckets[kind][bucket].add(disagreed)
+			}
+			for a := 0; a < len(movers); a++ {
+				for b := a + 1; b < len(movers); b++ {
+					pair := movers[a].Name + " vs " + movers[b].Name
+					if pairs[pair] == nil {
+						pairs[pair] = &disagreementTally{}
+					}
+					pairs[pair].add(moves[movers[a].Name] != moves[movers[b].Name])
+				}
+			}
+
+			if g.ledToDeath(i, *horizon) {
+				fatal = append(fatal, fatalPosition{g.key, logged.Turn, g.diedOn, g.played(i), moves})
+			}
+		}
+
+		last := g.states[len(g.states)-1]
+		last.Game.ID = "disagree/" + last.Game.ID
+		stopPondering(last)
+		forgetOpponents(last)
+		forgetTailChase(last)
+	}
+
+	fmt.Printf("%d games\n", len(games))
+	fmt.Printf("all        %s\n", total)
+	for _, kind := range []string{"phase", "snakes", "health"} {
+		fmt.Printf("\nby %s\n", kind)
+		names := make([]string, 0, len(buckets[kind]))
+		for name := range buckets[kind] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("  %-12s %s\n", name, buckets[kind][name])
+		}
+	}
+
+	fmt.Printf("\nby pair\n")
+	names := make([]string, 0, len(pairs))
+	for name := range pairs {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if pairs[names[i]].disagreements != pairs[names[j]].disagreements {
+			return pairs[names[i]].disagreements > pairs[names[j]].disagreements
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		fmt.Printf("  %-24s %s\n", name, pairs[name])
+	}
+
+	fmt.Printf("\nmoves that led to death within %d turns\n", *horizon)
+	for _, f := range fatal {
+		choices := make([]string, 0, len(movers))
+		for _, m := range movers {
+			mark := ""
+			if f.moves[m.Name] == f.played {
+				mark = "*"
+			}
+			choices = append(choices, fmt.Sprintf("%s %s%s", m.Name, f.moves[m.Name], mark))
+		}
+		fmt.Printf("  %s turn %d, played %s, died turn %d: %s\n", f.game, f.turn, f.played, f.diedOn, strings.Join(choices, ", "))
+	}
+	return nil
+}
+
+// readLoggedGames groups a log by game and snake, keeping the last record of
+// each turn unless only an earlier one has our move
+func readLoggedGames(r io.Reader) ([]loggedGame, error) {
+	byKey := make(map[string]map[int]LoggedState)
+	order := make([]string, 0)
+	err := scanLoggedStates(r, func(logged LoggedState) bool {
+		key := gameKey(logged.GameState)
+		if byKey[key] == nil {
+			byKey[key] = make(map[int]LoggedState)
+			order = append(order, key)
+		}
+		// an /end on the same turn doesn't replace the move we made
+		if previous, ok := byKey[key][logged.Turn]; ok && len(previous.Move) > 0 && len(logged.Move) == 0 {
+			return true
+		}
+		byKey[key][logged.Turn] = logged
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	games := make([]loggedGame, 0, len(order))
+	for _, key := range order {
+		g := loggedGame{key: key, diedOn: -1}
+		turns := make([]int, 0, len(byKey[key]))
+		for turn := range byKey[key] {
+			turns = append(turns, turn)
+		}
+		sort.Ints(turns)
+		for _, turn := range turns {
+			g.states = append(g.states, byKey[key][turn].GameState)
+			g.moves = append(g.moves, byKey[key][turn].Move)
+		}
+		// we were eliminated the turn after the last one we were on the board for,
+		// the log may carry on to the end of the game without us
+		alive := -1
+		for _, state := range g.states {
+			if hasSnake(state.You.ID, state.Board.Snakes) {
+				alive = state.Turn
+			}
+		}
+		if last := g.states[len(g.states)-1]; alive < last.Turn {
+			g.diedOn = alive + 1
+		}
+		games = append(games, g)
+	}
+	return games, nil
+}
+
+// situationBuckets places a position by game phase, snake count and our health
+func situationBuckets(state GameState) map[string]string {
+	phase := "1 early"
+	if state.Turn >= 150 {
+		phase = "3 late"
+	} else if state.Turn >= 50 {
+		phase = "2 mid"
+	}
+	snakes := "2 snakes"
+	switch n := len(state.Board.Snakes); {
+	case n == 1:
+		snakes = "1 snake"
+	case n >= 5:
+		snakes = "5+ snakes"
+	case n >= 3:
+		snakes = "3-4 snakes"
+	}
+	band := state.You.Health / 25 * 25
+	if band > 75 {
+		band = 75
+	}
+	return map[string]string{
+		"phase":  phase,
+		"snakes": snakes,
+		"health": fmt.Sprintf("health %02d+", band),
+	}
+}
+
+func hasSnake(id string, snakes []Battlesnake) bool {
+	_, ok := findSnake(id, snakes)
+	return ok
+}
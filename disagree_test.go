@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// loggedTurn is us heading right along row 5, alongside a snake that stays put
+func loggedTurn(game string, turn int, alive bool) GameState {
+	you := Battlesnake{ID: "you", Name: "you", Health: 90, Head: Coord{turn + 2, 5}, Body: []Coord{{turn + 2, 5}, {turn + 1, 5}, {turn, 5}}, Length: 3}
+	other := Battlesnake{ID: "other", Name: "other", Health: 90, Head: Coord{1, 1}, Body: []Coord{{1, 1}, {1, 0}, {0, 0}}, Length: 3}
+	board := Board{Width: 11, Height: 11, Food: []Coord{}, Hazards: []Coord{}, Snakes: []Battlesnake{other}}
+	if alive {
+		board.Snakes = append(board.Snakes, you)
+	}
+	return GameState{Game: Game{ID: game}, Turn: turn, Board: board, You: you}
+}
+
+func TestReadLoggedGames(t *testing.T) {
+	lines := make([]string, 0)
+	add := func(state GameState, move string) {
+		data, err := json.Marshal(LoggedState{GameState: state, Move: move})
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, string(data))
+	}
+	// eliminated on turn 5 by the move we logged on turn 4, the game carries
+	// on without us until turn 9
+	for turn := 0; turn < 5; turn++ {
+		add(loggedTurn("died", turn, true), "right")
+	}
+	add(loggedTurn("died", 9, false), "")
+	// a game we survived from a log without moves, the /end repeats the last turn
+	for turn := 0; turn < 3; turn++ {
+		add(loggedTurn("won", turn, true), "")
+	}
+	add(loggedTurn("won", 2, true), "")
+
+	games, err := readLoggedGames(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 2 {
+		t.Fatalf("got %d games, want 2", len(games))
+	}
+	died, won := games[0], games[1]
+	if died.diedOn != 5 {
+		t.Errorf("died on turn %d, want 5", died.diedOn)
+	}
+	if won.diedOn != -1 || len(won.states) != 3 {
+		t.Errorf("won game died on %d with %d turns, want -1 and 3", won.diedOn, len(won.states))
+	}
+
+	fatalTurns := func(g loggedGame, horizon int) []int {
+		turns := make([]int, 0)
+		for i := range g.states {
+			if g.ledToDeath(i, horizon) {
+				turns = append(turns, g.states[i].Turn)
+			}
+		}
+		return turns
+	}
+	for horizon, want := range map[int][]int{0: {}, 1: {4}, 2: {3, 4}} {
+		if got := fatalTurns(died, horizon); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("horizon %d led to death on turns %v, want %v", horizon, got, want)
+		}
+	}
+	if got := fatalTurns(won, 10); len(got) > 0 {
+		t.Errorf("a game we survived led to death on turns %v", got)
+	}
+	if won.played(0) != "right" || won.played(2) != "" {
+		t.Errorf("moves worked out from the heads are %q then %q, want right then none", won.played(0), won.played(2))
+	}
+}
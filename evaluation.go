@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+)
+
+// Feature scores a candidate move on its own, higher is better. Scores stay
+// roughly within -1..1 so weights are comparable across features.
+type Feature func(state GameState, move WeightedMovement) float64
+
+var features = map[string]Feature{
+	"space":     spaceFeature,
+	"food":      foodFeature,
+	"hunger":    hungerFeature,
+	"length":    lengthFeature,
+	"opponents": opponentFeature,
+	"center":    centerFeature,
+	"hazard":    hazardFeature,
+	"territory": territoryFeature,
+	"cutoff":    cutoffFeature,
+	"chamber":   chamberFeature,
+}
+
+// Strategy is a set of features and how much each one counts
+type Strategy struct {
+	Name    string             `json:"name"`
+	Weights map[string]float64 `json:"weights"`
+}
+
+var defaultStrategy = Strategy{
+	Name: "default",
+	Weights: map[string]float64{
+		"space":     4,
+		"hunger":    2,
+		"length":    1,
+		"opponents": 2,
+		"center":    0.5,
+		"hazard":    1,
+		"territory": 2,
+	},
+}
+
+// LoadStrategy reads a strategy from a json file, rejecting unknown features
+func LoadStrategy(path string) (Strategy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Strategy{}, err
+	}
+	var strategy Strategy
+	if err := json.Unmarshal(data, &strategy); err != nil {
+		return Strategy{}, fmt.Errorf("parsing strategy %s: %w", path, err)
+	}
+	for name := range strategy.Weights {
+		if _, ok := features[name]; !ok {
+			return Strategy{}, fmt.Errorf("strategy %s uses unknown feature %q", path, name)
+		}
+	}
+	return strategy, nil
+}
+
+// Score is the weighted sum of the strategy's features, along with each feature's raw score
+func (s Strategy) Score(state GameState, move WeightedMovement) (float64, map[string]float64) {
+	scores := make(map[string]float64, len(s.Weights))
+	total := 0.0
+	for _, name := range s.featureNames() {
+		score := features[name](state, move)
+		scores[name] = score
+		total += s.Weights[name] * score
+	}
+	return total, scores
+}
+
+// Best scores every move and returns the highest, ties go to the earlier move
+func (s Strategy) Best(state GameState, moves WeightedMovementSet, trace *Trace) WeightedMovement {
+	best := moves[0]
+	bestScore := 0.0
+	for i, move := range moves {
+		score, scores := s.Score(state, move)
+		log.Printf("[%s] %s scores %.3f %v", state.You.Name, move.movement.asString(), score, scores)
+		trace.Score(move.movement.asString(), score, scores)
+		if i == 0 || score > bestScore {
+			best = move
+			bestScore = score
+		}
+	}
+	return best
+}
+
+func (s Strategy) featureNames() []string {
+	names := make([]string, 0, len(s.Weights))
+	for name, weight := range s.Weights {
+		if weight != 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StrategyMover picks moves purely by the strategy's scores
+func StrategyMover(strategy Strategy) SnakeMoverFunc {
+	return func(state GameState) BattlesnakeMoveResponse {
+		trace := newTrace()
+		ctx, cancel := searchContext(state)
+		defer cancel()
+		possible := searchMoves(ctx, state)
+		trace.Phase("search")
+		trace.Consider(possible)
+		safe := possible.avoidCertainDeath()
+		trace.Keep(safe, "certain death")
+		if len(safe) == 0 {
+			log.Printf("[%s] No moves avoid certain death", state.You.Name)
+			return trace.Respond(BattlesnakeMoveResponse{Move: possible[0].movement.asString()}, "no safe moves")
+		}
+		best := strategy.Best(state, safe, trace)
+		return trace.Respond(BattlesnakeMoveResponse{Move: best.movement.asString()}, "strategy "+strategy.Name)
+	}
+}
+
+// loadStrategyFromEnv uses STRATEGY_FILE if set, otherwise the default strategy
+func loadStrategyFromEnv() Strategy {
+	path := os.Getenv("STRATEGY_FILE")
+	if len(path) == 0 {
+		return defaultStrategy
+	}
+	strategy, err := LoadStrategy(path)
+	if err != nil {
+		log.Printf("ERROR: Failed to load strategy, using default, %s", err)
+		return defaultStrategy
+	}
+	log.Printf("Loaded strategy %s from %s", strategy.Name, path)
+	return strategy
+}
+
+func opponents(state GameState) []Battlesnake {
+	others := make([]Battlesnake, 0, len(state.Board.Snakes))
+	for _, s := range state.Board.Snakes {
+		if s.ID != state.You.ID {
+			others = append(others, s)
+		}
+	}
+	return others
+}
+
+// share of the board reachable after the move, at least room for our body
+// if the search didn't finish
+func spaceFeature(state GameState, move WeightedMovement) float64 {
+	cells := state.Board.Width * state.Board.Height
+	if cells == 0 {
+		return 0
+	}
+	open := len(move.open)
+	if move.partial && open < state.You.Length {
+		open = state.You.Length
+	}
+	return float64(open) / float64(cells)
+}
+
+// closeness of the nearest food
+func foodFeature(state GameState, move WeightedMovement) float64 {
+	if len(state.Board.Food) == 0 {
+		return 0
+	}
+	return 1 / float64(1+distanceTo(move.root, nearest(move.root, state.Board.Food)))
+}
+
+// closeness of the nearest food, counting for more the hungrier we are
+func hungerFeature(state GameState, move WeightedMovement) float64 {
+	return foodFeature(state, move) * float64(100-state.You.Health) / 100
+}
+
+// how much longer we are than the longest opponent after the move
+func lengthFeature(state GameState, move WeightedMovement) float64 {
+	length := state.You.Length
+	if hasFood(move.root, state.Board.Food) {
+		length++
+	}
+	longest := 0
+	for _, s := range opponents(state) {
+		if s.Length > longest {
+			longest = s.Length
+		}
+	}
+	return float64(length-longest) / float64(length+longest)
+}
+
+// nearby heads of snakes that can beat us count against, smaller ones for
+func opponentFeature(state GameState, move WeightedMovement) float64 {
+	score := 0.0
+	for _, s := range opponents(state) {
+		d := distanceTo(move.root, s.Head)
+		if d == 0 || d > 2 {
+			continue
+		}
+		if s.Length >= state.You.Length {
+			score -= 1 / float64(d)
+		} else {
+			score += 0.5 / float64(d)
+		}
+	}
+	return score
+}
+
+// closeness to the middle of the board
+func centerFeature(state GameState, move WeightedMovement) float64 {
+	middle := Coord{(state.Board.Width - 1) / 2, (state.Board.Height - 1) / 2}
+	// the far corner, boards with an even side are one further out on that side
+	farthest := distanceTo(middle, Coord{state.Board.Width - 1, state.Board.Height - 1})
+	if farthest == 0 {
+		return 1
+	}
+	return 1 - float64(distanceTo(move.root, middle))/float64(farthest)
+}
+
+// hazard damage taken by the move as a share of our health
+func hazardFeature(state GameState, move WeightedMovement) float64 {
+	stacks := 0
+	for _, h := range state.Board.Hazards {
+		if h == move.root {
+			stacks++
+		}
+	}
+	if stacks == 0 || state.You.Health == 0 {
+		return 0
+	}
+	damage := stacks * state.Game.Ruleset.Settings.HazardDamagePerTurn
+	return -float64(damage) / float64(state.You.Health)
+}
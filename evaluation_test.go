@@ -0,0 +1,93 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeStrategy(t *testing.T, json string) string {
+	path := filepath.Join(t.TempDir(), "strategy.json")
+	if err := os.WriteFile(path, []byte(json), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadStrategy(t *testing.T) {
+	strategy, err := LoadStrategy(writeStrategy(t, `{"name": "greedy", "weights": {"food": 3, "space": 1}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strategy.Name != "greedy" || len(strategy.Weights) != 2 || strategy.Weights["food"] != 3 {
+		t.Errorf("loaded %+v", strategy)
+	}
+
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"unknown feature", `{"name": "bad", "weights": {"luck": 1}}`},
+		{"not json", `{"name": `},
+	}
+	for _, tt := range tests {
+		if _, err := LoadStrategy(writeStrategy(t, tt.json)); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+	if _, err := LoadStrategy(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
+
+func TestLoadStrategyFromEnv(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	t.Setenv("STRATEGY_FILE", "")
+	if got := loadStrategyFromEnv(); got.Name != defaultStrategy.Name {
+		t.Errorf("no STRATEGY_FILE loaded %q, want the default", got.Name)
+	}
+
+	t.Setenv("STRATEGY_FILE", writeStrategy(t, `{"name": "greedy", "weights": {"food": 3}}`))
+	if got := loadStrategyFromEnv(); got.Name != "greedy" {
+		t.Errorf("loaded %q from STRATEGY_FILE, want greedy", got.Name)
+	}
+
+	t.Setenv("STRATEGY_FILE", writeStrategy(t, `{"name": "bad", "weights": {"luck": 1}}`))
+	if got := loadStrategyFromEnv(); got.Name != defaultStrategy.Name {
+		t.Errorf("a bad STRATEGY_FILE loaded %q, want the default", got.Name)
+	}
+}
+
+func TestStrategyScore(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	board, err := ParseASCIIBoard(`
+		.....
+		.*A..
+		..a..
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	you, _ := findSnake("A", board.Snakes)
+	state := GameState{Game: Game{ID: t.Name()}, Board: board, You: you}
+	moves := fillToDepth(you.Head, you.Length, board).avoidCertainDeath()
+
+	greedy := Strategy{Name: "greedy", Weights: map[string]float64{"food": 2, "center": 0}}
+	for _, m := range moves {
+		total, scores := greedy.Score(state, m)
+		if _, ok := scores["center"]; ok {
+			t.Errorf("scored center with no weight, %v", scores)
+		}
+		if total != 2*scores["food"] {
+			t.Errorf("%s total %v, want twice food %v", m.movement.asString(), total, scores["food"])
+		}
+	}
+	if best := greedy.Best(state, moves, nil); best.movement != Left {
+		t.Errorf("greedy went %s, want left onto the food", best.movement.asString())
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+const (
+	weirdLengthOne = 1 << iota
+	weirdStacked
+	weirdNoFood
+	weirdFullHazards
+)
+
+// weirdState builds a valid but unusual position: tiny or 1xN boards,
+// length 1 snakes, snakes stacked up at the start of a game, no food, hazards everywhere
+func weirdState(width, height, snakes uint8, seed int64, weird uint8) GameState {
+	rng := rand.New(rand.NewSource(seed))
+	spec := BoardSpec{
+		Width:        1 + int(width)%19,
+		Height:       1 + int(height)%19,
+		Snakes:       1 + int(snakes)%8,
+		MinLength:    2,
+		MaxLength:    7,
+		MinHealth:    1,
+		MaxHealth:    100,
+		FoodDensity:  0.05,
+		MinFood:      1,
+		HazardDamage: rng.Intn(20),
+		Turn:         rng.Intn(300),
+	}
+	switch {
+	case weird&weirdLengthOne != 0:
+		spec.MinLength, spec.MaxLength = 1, 1
+	case weird&weirdStacked != 0:
+		spec.MinLength, spec.MaxLength = 3, 3
+		spec.Stacked = true
+	}
+	if weird&weirdNoFood != 0 {
+		spec.FoodDensity, spec.MinFood = 0, 0
+	}
+	if weird&weirdFullHazards != 0 {
+		spec.Hazards = FullHazards
+	}
+
+	state := GenerateState(spec, rng)
+	state.Game.ID = fmt.Sprintf("fuzz-%d", seed)
+	return state
+}
+
+// fatalMove is true if the move certainly kills us: off the board, into a
+// body that will still be there, or into a hazard that takes the last of our health
+func fatalMove(state GameState, move string) bool {
+	movement, ok := parseMovement(move)
+	if !ok {
+		return true
+	}
+	next := moveCoord(state.You.Head, movement)
+	if !makeTimedMap(state.Board).freeAt(next, 1) {
+		return true
+	}
+	return lethalStep(next, state.Board, state.You.Health, state.Game.Ruleset.Settings.HazardDamagePerTurn)
+}
+
+func FuzzMovers(f *testing.F) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	f.Add(uint8(10), uint8(10), uint8(3), int64(1), uint8(0))
+	f.Add(uint8(6), uint8(6), uint8(3), int64(2), uint8(weirdLengthOne))
+	f.Add(uint8(10), uint8(10), uint8(7), int64(3), uint8(weirdStacked))
+	f.Add(uint8(0), uint8(10), uint8(1), int64(4), uint8(0))
+	f.Add(uint8(10), uint8(0), uint8(2), int64(5), uint8(weirdNoFood))
+	f.Add(uint8(10), uint8(10), uint8(3), int64(6), uint8(weirdFullHazards))
+	f.Add(uint8(1), uint8(1), uint8(1), int64(7), uint8(weirdStacked|weirdNoFood|weirdFullHazards))
+
+	f.Fuzz(func(t *testing.T, width, height, snakes uint8, seed int64, weird uint8) {
+		state := weirdState(width, height, snakes, seed, weird)
+
+		survivable := false
+		for _, m := range []string{"up", "down", "left", "right"} {
+			if !fatalMove(state, m) {
+				survivable = true
+			}
+		}
+
+		for _, m := range registeredMovers() {
+			response := m.Mover(state)
+			switch response.Move {
+			case "up", "down", "left", "right":
+			default:
+				t.Fatalf("%s returned %q", m.Name, response.Move)
+			}
+			if survivable && fatalMove(state, response.Move) {
+				t.Errorf("%s chose fatal move %s with a way out, board %+v", m.Name, response.Move, state.Board)
+			}
+		}
+	})
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// HazardLayout is where a generated board puts its hazards
+type HazardLayout int
+
+const (
+	NoHazards HazardLayout = iota
+	// HazardDensity of the cells, anywhere
+	ScatteredHazards
+	// HazardDepth cells in from every edge, as royale closes in
+	RingHazards
+	FullHazards
+)
+
+// BoardSpec describes the kind of position GenerateState builds.
+// Zero values fall back to a standard 11x11 board of length 3 snakes on full health.
+type BoardSpec struct {
+	Width  int
+	Height int
+	Snakes int
+	// lengths are drawn from MinLength to MaxLength, a snake boxed in before then stays shorter
+	MinLength int
+	MaxLength int
+	MinHealth int
+	MaxHealth int
+	// length 3 snakes stacked on the standard starting spots, with the starting food
+	StandardStart bool
+	// every snake's body stacked on its head, as on the first turn
+	Stacked bool
+	// share of the free cells with food, and the least food there will be
+	FoodDensity float64
+	MinFood     int
+	Hazards     HazardLayout
+	// share of the cells for ScatteredHazards
+	HazardDensity float64
+	// how deep the ring is for RingHazards
+	HazardDepth  int
+	HazardDamage int
+	Turn         int
+}
+
+func (spec BoardSpec) withDefaults() BoardSpec {
+	if spec.Width <= 0 {
+		spec.Width = 11
+	}
+	if spec.Height <= 0 {
+		spec.Height = 11
+	}
+	if spec.MinLength <= 0 {
+		spec.MinLength = 3
+	}
+	if spec.MaxLength < spec.MinLength {
+		spec.MaxLength = spec.MinLength
+	}
+	if spec.MinHealth <= 0 {
+		spec.MinHealth = 100
+	}
+	if spec.MaxHealth < spec.MinHealth {
+		spec.MaxHealth = spec.MinHealth
+	}
+	if spec.StandardStart {
+		spec.MinLength, spec.MaxLength = 3, 3
+		spec.MinHealth, spec.MaxHealth = 100, 100
+		spec.Stacked = true
+	}
+	return spec
+}
+
+// GenerateState builds a random, legal position from the spec. Every snake's
+// Head is Body[0] and Length is len(Body), and no two snakes or food overlap.
+// Snakes that don't fit on the board are left out. The first snake is You.
+func GenerateState(spec BoardSpec, rng *rand.Rand) GameState {
+	spec = spec.withDefaults()
+	board := Board{Width: spec.Width, Height: spec.Height, Food: make([]Coord, 0), Hazards: make([]Coord, 0), Snakes: make([]Battlesnake, 0)}
+	taken := make(map[Coord]bool)
+
+	starts := standardStarts(spec.Width, spec.Height)
+	rng.Shuffle(len(starts), func(i, j int) { starts[i], starts[j] = starts[j], starts[i] })
+	for i := 0; i < spec.Snakes; i++ {
+		var head Coord
+		var ok bool
+		if spec.StandardStart && i < len(starts) {
+			head, ok = starts[i], !taken[starts[i]]
+		} else {
+			head, ok = randomFree(board, taken, rng)
+		}
+		if !ok {
+			break
+		}
+		taken[head] = true
+
+		want := spec.MinLength + rng.Intn(spec.MaxLength-spec.MinLength+1)
+		body := []Coord{head}
+		if spec.Stacked {
+			for len(body) < want {
+				body = append(body, head)
+			}
+		}
+		for len(body) < want {
+			options := make([]Coord, 0, 4)
+			for _, n := range makeNextMoves(body[len(body)-1]) {
+				if !isOffBoard(n, board) && !taken[n] {
+					options = append(options, n)
+				}
+			}
+			if len(options) == 0 {
+				break
+			}
+			next := options[rng.Intn(len(options))]
+			taken[next] = true
+			body = append(body, next)
+		}
+
+		id := fmt.Sprintf("snake-%d", i)
+		board.Snakes = append(board.Snakes, Battlesnake{
+			ID:     id,
+			Name:   id,
+			Health: spec.MinHealth + rng.Intn(spec.MaxHealth-spec.MinHealth+1),
+			Body:   body,
+			Head:   head,
+			Length: len(body),
+		})
+	}
+
+	if spec.StandardStart {
+		placeStartingFood(&board, taken)
+	}
+	free := spec.Width*spec.Height - len(taken)
+	food := int(spec.FoodDensity * float64(free))
+	if food < spec.MinFood-len(board.Food) {
+		food = spec.MinFood - len(board.Food)
+	}
+	for i := 0; i < food; i++ {
+		c, ok := randomFree(board, taken, rng)
+		if !ok {
+			break
+		}
+		taken[c] = true
+		board.Food = append(board.Food, c)
+	}
+
+	board.Hazards = hazardLayout(spec, rng)
+
+	state := GameState{
+		Game: Game{
+			ID:      fmt.Sprintf("generated-%d", rng.Int63()),
+			Timeout: int(defaultMoveTimeout.Milliseconds()),
+			Ruleset: Ruleset{Name: "standard", Settings: RulesetSettings{HazardDamagePerTurn: spec.HazardDamage}},
+		},
+		Turn:  spec.Turn,
+		Board: board,
+	}
+	if len(board.Snakes) > 0 {
+		state.You = board.Snakes[0]
+	}
+	return state
+}
+
+// standardStarts are the corner and edge spots snakes start on, one in from the walls
+func standardStarts(width, height int) []Coord {
+	if width < 3 || height < 3 {
+		return nil
+	}
+	mx, my := width-2, height-2
+	cx, cy := (width-1)/2, (height-1)/2
+	starts := []Coord{{1, 1}, {mx, my}, {1, my}, {mx, 1}}
+	for _, c := range []Coord{{cx, 1}, {cx, my}, {1, cy}, {mx, cy}} {
+		if !hasCoord(c, starts) {
+			starts = append(starts, c)
+		}
+	}
+	return starts
+}
+
+// placeStartingFood puts a food diagonal to each snake and one in the middle
+func placeStartingFood(board *Board, taken map[Coord]bool) {
+	for _, s := range board.Snakes {
+		for _, f := range []Coord{{s.Head.X - 1, s.Head.Y - 1}, {s.Head.X + 1, s.Head.Y + 1}, {s.Head.X - 1, s.Head.Y + 1}, {s.Head.X + 1, s.Head.Y - 1}} {
+			if !isOffBoard(f, *board) && !taken[f] {
+				taken[f] = true
+				board.Food = append(board.Food, f)
+				break
+			}
+		}
+	}
+	center := Coord{(board.Width - 1) / 2, (board.Height - 1) / 2}
+	if !taken[center] {
+		taken[center] = true
+		board.Food = append(board.Food, center)
+	}
+}
+
+func randomFree(board Board, taken map[Coord]bool, rng *rand.Rand) (Coord, bool) {
+	for tries := 0; tries < 100; tries++ {
+		c := Coord{rng.Intn(board.Width), rng.Intn(board.Height)}
+		if !taken[c] {
+			return c, true
+		}
+	}
+	// crowded, pick from what's left
+	free := make([]Coord, 0)
+	for x := 0; x < board.Width; x++ {
+		for y := 0; y < board.Height; y++ {
+			if c := (Coord{x, y}); !taken[c] {
+				free = append(free, c)
+			}
+		}
+	}
+	if len(free) == 0 {
+		return Coord{}, false
+	}
+	return free[rng.Intn(len(free))], true
+}
+
+// freeCells are the cells with no snake or food on them
+func freeCells(board Board) []Coord {
+	taken := make(map[Coord]bool)
+	for _, f := range board.Food {
+		taken[f] = true
+	}
+	for _, s := range board.Snakes {
+		for _, c := range s.Body {
+			taken[c] = true
+		}
+	}
+	free := make([]Coord, 0)
+	for x := 0; x < board.Width; x++ {
+		for y := 0; y < board.Height; y++ {
+			if c := (Coord{x, y}); !taken[c] {
+				free = append(free, c)
+			}
+		}
+	}
+	return free
+}
+
+func hazardLayout(spec BoardSpec, rng *rand.Rand) []Coord {
+	hazards := make([]Coord, 0)
+	for x := 0; x < spec.Width; x++ {
+		for y := 0; y < spec.Height; y++ {
+			edge := x
+			for _, d := range []int{y, spec.Width - 1 - x, spec.Height - 1 - y} {
+				if d < edge {
+					edge = d
+				}
+			}
+			switch spec.Hazards {
+			case ScatteredHazards:
+				if rng.Float64() < spec.HazardDensity {
+					hazards = append(hazards, Coord{x, y})
+				}
+			case RingHazards:
+				if edge < spec.HazardDepth {
+					hazards = append(hazards, Coord{x, y})
+				}
+			case FullHazards:
+				hazards = append(hazards, Coord{x, y})
+			}
+		}
+	}
+	return hazards
+}
+
+// checkState reports the first way the state breaks the rules of a legal position
+func checkState(state GameState) error {
+	board := state.Board
+	cells := make(map[Coord]string)
+	for _, s := range board.Snakes {
+		if len(s.Body) == 0 {
+			return fmt.Errorf("%s has no body", s.ID)
+		}
+		if s.Head != s.Body[0] {
+			return fmt.Errorf("%s head %v isn't its first segment %v", s.ID, s.Head, s.Body[0])
+		}
+		if s.Length != len(s.Body) {
+			return fmt.Errorf("%s length %d, but %d segments", s.ID, s.Length, len(s.Body))
+		}
+		for i, c := range s.Body {
+			if isOffBoard(c, board) {
+				return fmt.Errorf("%s segment %d at %v is off the board", s.ID, i, c)
+			}
+			if i > 0 && c != s.Body[i-1] && distanceTo(c, s.Body[i-1]) != 1 {
+				return fmt.Errorf("%s segment %d at %v isn't next to the one before", s.ID, i, c)
+			}
+			if owner, ok := cells[c]; ok && owner != s.ID {
+				return fmt.Errorf("%s and %s overlap at %v", s.ID, owner, c)
+			}
+			cells[c] = s.ID
+		}
+	}
+	for _, f := range board.Food {
+		if isOffBoard(f, board) {
+			return fmt.Errorf("food at %v is off the board", f)
+		}
+		if owner, ok := cells[f]; ok {
+			return fmt.Errorf("food at %v is under %s", f, owner)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestGenerateStateIsLegal(t *testing.T) {
+	specs := map[string]BoardSpec{
+		"defaults":  {Snakes: 4},
+		"crowded":   {Width: 7, Height: 7, Snakes: 8, MinLength: 3, MaxLength: 12, FoodDensity: 0.5},
+		"one wide":  {Width: 1, Height: 9, Snakes: 3, MinLength: 1, MaxLength: 4, MinFood: 2},
+		"stacked":   {Width: 19, Height: 19, Snakes: 8, MinLength: 5, Stacked: true},
+		"royale":    {Snakes: 4, MinLength: 3, MaxLength: 10, Hazards: RingHazards, HazardDepth: 2, HazardDamage: 14},
+		"scattered": {Width: 11, Height: 7, Snakes: 2, Hazards: ScatteredHazards, HazardDensity: 0.3},
+	}
+	for name, spec := range specs {
+		for seed := int64(0); seed < 50; seed++ {
+			state := GenerateState(spec, rand.New(rand.NewSource(seed)))
+			if err := checkState(state); err != nil {
+				t.Fatalf("%s seed %d: %s", name, seed, err)
+			}
+			if len(state.Board.Snakes) > 0 && state.You.ID != state.Board.Snakes[0].ID {
+				t.Fatalf("%s seed %d: you aren't the first snake", name, seed)
+			}
+			if len(state.Board.Food) < spec.MinFood && len(freeCells(state.Board)) > 0 {
+				t.Fatalf("%s seed %d: %d food, want at least %d", name, seed, len(state.Board.Food), spec.MinFood)
+			}
+		}
+	}
+}
+
+func TestGenerateStandardStart(t *testing.T) {
+	state := GenerateState(BoardSpec{Snakes: 4, StandardStart: true}, rand.New(rand.NewSource(1)))
+	if err := checkState(state); err != nil {
+		t.Fatal(err)
+	}
+	starts := standardStarts(11, 11)
+	for _, s := range state.Board.Snakes {
+		if !hasCoord(s.Head, starts) {
+			t.Errorf("%s starts at %v, not a standard spot", s.ID, s.Head)
+		}
+		if s.Length != 3 || s.Health != 100 || s.Body[1] != s.Head || s.Body[2] != s.Head {
+			t.Errorf("%s should start stacked, length 3 on full health, got %+v", s.ID, s)
+		}
+	}
+	if !hasCoord(Coord{5, 5}, state.Board.Food) || len(state.Board.Food) != 5 {
+		t.Errorf("want food by each snake and in the middle, got %v", state.Board.Food)
+	}
+}
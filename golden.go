@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// where golden decisions are kept, golden_test.go plays every one of them
+const goldenDir = "testdata/golden"
+
+// GoldenCase is a position and the move we've decided is right there.
+// Either Move must be chosen, or none of Avoid may be.
+type GoldenCase struct {
+	Name  string   `json:"name"`
+	Note  string   `json:"note,omitempty"`
+	Move  string   `json:"move,omitempty"`
+	Avoid []string `json:"avoid,omitempty"`
+	// movers held to the decision, every registered mover when empty
+	Movers []string  `json:"movers,omitempty"`
+	State  GameState `json:"state"`
+}
+
+// Check returns why the move breaks the decision, or "" if it doesn't
+func (g GoldenCase) Check(move string) string {
+	if len(g.Move) > 0 && move != g.Move {
+		return fmt.Sprintf("moved %s, want %s", move, g.Move)
+	}
+	for _, avoid := range g.Avoid {
+		if move == avoid {
+			return fmt.Sprintf("moved %s, should avoid %v", move, g.Avoid)
+		}
+	}
+	return ""
+}
+
+// Applies is true if the named mover is held to the decision
+func (g GoldenCase) Applies(mover string) bool {
+	if len(g.Movers) == 0 {
+		return true
+	}
+	for _, m := range g.Movers {
+		if m == mover {
+			return true
+		}
+	}
+	return false
+}
+
+// golden turns one turn of a JSONL game log into a golden case, e.g.
+//
+//	go run . golden -input game.jsonl -game 1a2b -turn 57 -avoid up,left -note "walked into a dead end"
+func golden(args []string) error {
+	flags := flag.NewFlagSet("golden", flag.ContinueOnError)
+	input := flags.String("input", "-", "JSONL of GameStates, - for stdin")
+	turn := flags.Int("turn", -1, "turn to keep")
+	game := flags.String("game", "", "game ID, when the log has more than one game")
+	snake := flags.String("snake", "", "name of our snake, when the log has more than one of them")
+	move := flags.String("move", "", "the move that should be made")
+	avoid := flags.String("avoid", "", "comma separated moves that shouldn't be made")
+	movers := flags.String("movers", "", "comma separated movers held to it, default all")
+	name := flags.String("name", "", "case name, default <game>-<turn>")
+	note := flags.String("note", "", "why this is the right call")
+	dir := flags.String("dir", goldenDir, "where cases are written")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *turn < 0 {
+		return fmt.Errorf("-turn is required")
+	}
+	if len(*move) == 0 && len(*avoid) == 0 {
+		return fmt.Errorf("one of -move or -avoid is required")
+	}
+	for _, m := range append(splitList(*avoid), *move) {
+		if _, ok := parseMovement(m); !ok && len(m) > 0 {
+			return fmt.Errorf("%q isn't a move", m)
+		}
+	}
+	known := make(map[string]bool)
+	for _, m := range registeredMovers() {
+		known[m.Name] = true
+	}
+	for _, m := range splitList(*movers) {
+		if !known[m] {
+			return fmt.Errorf("unknown mover %q", m)
+		}
+	}
+
+	var r io.Reader = os.Stdin
+	if *input != "-" {
+		f, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	state, err := findLoggedTurn(r, *turn, *game, *snake)
+	if err != nil {
+		return err
+	}
+
+	c := GoldenCase{
+		Name:   *name,
+		Note:   *note,
+		Move:   *move,
+		Avoid:  splitList(*avoid),
+		Movers: splitList(*movers),
+		State:  state,
+	}
+	if len(c.Name) == 0 {
+		c.Name = fmt.Sprintf("%s-%d", state.Game.ID, state.Turn)
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(*dir, c.Name+".json")
+	fmt.Printf("Writing %s\n", path)
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// LoadGoldenCases reads every case in the directory
+func LoadGoldenCases(dir string) ([]GoldenCase, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	cases := make([]GoldenCase, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var c GoldenCase
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
+
+// findLoggedTurn scans a JSONL log for the turn we moved on, in the given game
+// and as seen by the named snake if they're given. /end is logged after the
+// last move, so only the first record of a turn counts, and records without
+// us on the board are skipped.
+func findLoggedTurn(r io.Reader, turn int, game, snake string) (GameState, error) {
+	found := make(map[string]GameState)
+	keys := make([]string, 0)
+	err := scanLoggedStates(r, func(logged LoggedState) bool {
+		state := logged.GameState
+		if state.Turn != turn || (len(game) > 0 && state.Game.ID != game) || (len(snake) > 0 && state.You.Name != snake) {
+			return true
+		}
+		if _, ok := found[gameKey(state)]; ok || !hasSnake(state.You.ID, state.Board.Snakes) {
+			return true
+		}
+		found[gameKey(state)] = state
+		keys = append(keys, gameKey(state))
+		return true
+	})
+	if err != nil {
+		return GameState{}, err
+	}
+	switch len(keys) {
+	case 0:
+		return GameState{}, fmt.Errorf("turn %d not found", turn)
+	case 1:
+		return found[keys[0]], nil
+	}
+	return GameState{}, fmt.Errorf("turn %d is in more than one game or snake, %s, pick one with -game and -snake", turn, strings.Join(keys, ", "))
+}
+
+// scanLoggedStates calls fn with each line of a JSONL log until it returns false
+func scanLoggedStates(r io.Reader, fn func(logged LoggedState) bool) error {
+	scanner := bufio.NewScanner(r)
+	// a 19x19 board full of snakes is a long line
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 {
+			continue
+		}
+		var logged LoggedState
+		if err := json.Unmarshal([]byte(text), &logged); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if !fn(logged) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+func splitList(list string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			items = append(items, item)
+		}
+	}
+	return items
+}
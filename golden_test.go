@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestGoldenDecisions plays every recorded decision in testdata/golden,
+// add more with `go run . golden`
+func TestGoldenDecisions(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	cases, err := LoadGoldenCases(goldenDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) == 0 {
+		t.Skip("no golden cases")
+	}
+
+	for _, c := range cases {
+		for _, m := range registeredMovers() {
+			if !c.Applies(m.Name) {
+				continue
+			}
+			c, m := c, m
+			t.Run(c.Name+"/"+m.Name, func(t *testing.T) {
+				if problem := c.Check(m.Mover(c.State).Move); len(problem) > 0 {
+					t.Errorf("%s, %s", problem, c.Note)
+				}
+			})
+		}
+	}
+}
+
+func TestFindLoggedTurn(t *testing.T) {
+	lines := make([]string, 0)
+	for _, state := range []GameState{
+		loggedTurn("first", 2, true),
+		// /end of the first game, logged on the turn we last moved
+		loggedTurn("first", 2, false),
+		loggedTurn("second", 1, true),
+		loggedTurn("second", 2, true),
+		// eliminated, the game ended without us
+		loggedTurn("third", 2, false),
+	} {
+		data, err := json.Marshal(state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, string(data))
+	}
+	logged := strings.Join(lines, "\n")
+
+	if _, err := findLoggedTurn(strings.NewReader(logged), 2, "", ""); err == nil {
+		t.Errorf("expected an error when two games reach the turn")
+	}
+	state, err := findLoggedTurn(strings.NewReader(logged), 2, "first", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasSnake(state.You.ID, state.Board.Snakes) {
+		t.Errorf("found the /end record instead of the move")
+	}
+	if state, err := findLoggedTurn(strings.NewReader(logged), 1, "", "you"); err != nil || state.Game.ID != "second" {
+		t.Errorf("turn 1 found %s, %v", state.Game.ID, err)
+	}
+	if _, err := findLoggedTurn(strings.NewReader(logged), 2, "third", ""); err == nil {
+		t.Errorf("expected an error when we weren't on the board")
+	}
+	if _, err := findLoggedTurn(strings.NewReader(logged), 3, "", ""); err == nil {
+		t.Errorf("expected an error for a turn that isn't logged")
+	}
+}
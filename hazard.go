@@ -0,0 +1,109 @@
+package main
+
+import "container/heap"
+
+// hazardStacks counts the hazards on each cell, the same cell can be listed more than once
+func hazardStacks(board Board) map[Coord]int {
+	stacks := make(map[Coord]int, len(board.Hazards))
+	for _, h := range board.Hazards {
+		stacks[h]++
+	}
+	return stacks
+}
+
+// stepDamage is the health lost moving onto c, the usual 1 plus any hazard damage.
+// Eating restores health, so food is free.
+func stepDamage(c Coord, stacks map[Coord]int, hazardDamage int, food []Coord) int {
+	if hasFood(c, food) {
+		return 0
+	}
+	return 1 + stacks[c]*hazardDamage
+}
+
+// health a snake has after eating
+const maxHealth = 100
+
+// healthCost is the health lost walking the coords from health, up to and
+// including the last one, negative if we eat on the way, and whether we live
+// through every step
+func healthCost(coords []Coord, board Board, health, hazardDamage int) (int, bool) {
+	stacks := hazardStacks(board)
+	left := health
+	alive := true
+	for _, c := range coords {
+		left -= stepDamage(c, stacks, hazardDamage, board.Food)
+		if left <= 0 {
+			alive = false
+		}
+		if hasFood(c, board.Food) {
+			left = maxHealth
+		}
+	}
+	return health - left, alive
+}
+
+// lethalStep is true when moving onto c with health left kills us
+func lethalStep(c Coord, board Board, health, hazardDamage int) bool {
+	return health-stepDamage(c, hazardStacks(board), hazardDamage, board.Food) <= 0
+}
+
+// reachableWithHealth returns every cell we can reach from start before the
+// hazards (and hunger) use up our health. Bodies block until they've moved on.
+func reachableWithHealth(start Coord, board Board, health, hazardDamage int) Coords {
+	timed := makeTimedMap(board)
+	stacks := hazardStacks(board)
+	spent := map[Coord]int{start: 0}
+	turns := map[Coord]int{start: 0}
+	open := &pathQueue{}
+	heap.Push(open, pathNode{coord: start})
+
+	reached := make(Coords, 0)
+	for open.Len() > 0 {
+		node := heap.Pop(open).(pathNode)
+		curr := node.coord
+		if node.priority > spent[curr] {
+			continue
+		}
+		if curr != start {
+			reached = append(reached, curr)
+		}
+		for _, next := range makeNextMoves(curr) {
+			if !timed.freeAt(next, turns[curr]+1) {
+				continue
+			}
+			cost := spent[curr] + stepDamage(next, stacks, hazardDamage, nil)
+			if cost >= health {
+				continue
+			}
+			if known, ok := spent[next]; ok && known <= cost {
+				continue
+			}
+			spent[next] = cost
+			turns[next] = turns[curr] + 1
+			heap.Push(open, pathNode{coord: next, priority: cost})
+		}
+	}
+	return reached
+}
+
+// survivableSteps drops the next cells where hazards would finish us off
+func survivableSteps(state GameState, steps []Coord) []Coord {
+	damage := state.Game.Ruleset.Settings.HazardDamagePerTurn
+	survivable := make([]Coord, 0, len(steps))
+	for _, c := range steps {
+		if !lethalStep(c, state.Board, state.You.Health, damage) {
+			survivable = append(survivable, c)
+		}
+	}
+	return survivable
+}
+
+// markLethalHazards rules out moves where the hazard damage alone would kill us
+func markLethalHazards(state GameState, moves WeightedMovementSet) {
+	damage := state.Game.Ruleset.Settings.HazardDamagePerTurn
+	for i := range moves {
+		if !moves[i].certainDeath && lethalStep(moves[i].root, state.Board, state.You.Health, damage) {
+			moves[i].certainDeath = true
+		}
+	}
+}
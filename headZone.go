@@ -1,66 +1,170 @@
 package main
 
+// HeadToHead is how meeting a snake head-on ends for us
+type HeadToHead int
+
+const (
+	WeWin HeadToHead = iota
+	BothDie
+	WeLose
+)
+
+// ZoneCell is a cell a snake's head can reach, the earliest turn it can get
+// there and how meeting it there ends for us
+type ZoneCell struct {
+	Coord   Coord
+	Turn    int
+	Outcome HeadToHead
+}
+
 type HeadZone struct {
+	SnakeID     string
 	SnakeHead   Coord
 	SnakeLength int
 	SnakeName   string
 	Zone        []Coord
+	Cells       []ZoneCell
+	// chance of the head moving onto each neighbouring cell next turn
+	Odds map[Coord]float64
 }
 
-func MakeHeadZones(snakes []Battlesnake, board Board, depthLimit int) []HeadZone {
+// MakeHeadZones maps where each snake FindOtherSnakes described can get its
+// head within depthLimit moves, and how meeting it there ends for you
+func MakeHeadZones(others []OtherSnake, you Battlesnake, board Board, depthLimit int) []HeadZone {
 	zones := make([]HeadZone, 0)
-	for _, s := range snakes {
-		zones = append(zones, MakeHeadZone(s, board, depthLimit))
+	timed := makeTimedMap(board)
+	for _, s := range others {
+		zones = append(zones, MakeHeadZone(s, you, board, timed, depthLimit))
 	}
 
 	return zones
 }
 
-func MakeHeadZone(snake Battlesnake, board Board, depthLimit int) HeadZone {
+// headZonesFor maps every opponent's head zone for a move, strategies build
+// these once and pass them down, cut to depth with headZonesWithin
+func headZonesFor(state GameState, depthLimit int) []HeadZone {
+	return MakeHeadZones(FindOtherSnakes(opponents(state), state.Board), state.You, state.Board, depthLimit)
+}
+
+// headZonesWithin cuts the zones down to the cells a head reaches within depth moves
+func headZonesWithin(zones []HeadZone, depth int) []HeadZone {
+	cut := make([]HeadZone, len(zones))
+	for i, z := range zones {
+		z.Zone = make([]Coord, 0, len(z.Zone))
+		z.Cells = make([]ZoneCell, 0, len(z.Cells))
+		for _, cell := range zones[i].Cells {
+			if cell.Turn <= depth {
+				z.Zone = append(z.Zone, cell.Coord)
+				z.Cells = append(z.Cells, cell)
+			}
+		}
+		cut[i] = z
+	}
+	return cut
+}
+
+// weighHeadZones fills in each zone's next move odds from the opponent model
+func weighHeadZones(state GameState, zones []HeadZone) {
+	for i := range zones {
+		for _, s := range state.Board.Snakes {
+			if s.ID == zones[i].SnakeID {
+				zones[i].Odds = OpponentMoveProbabilities(state, s)
+			}
+		}
+	}
+}
+
+// MakeHeadZone maps where the snake's head can be within depthLimit moves.
+// Each cell's outcome counts the most food the snake could eat on the way there.
+func MakeHeadZone(snake OtherSnake, you Battlesnake, board Board, timed TimedMap, depthLimit int) HeadZone {
 	head := snake.Head
-	length := snake.Length
-	name := snake.Name
-	body := snake.Body[1:]
+	zone := HeadZone{SnakeID: snake.ID, SnakeHead: head, SnakeLength: snake.Length, SnakeName: snake.Name, Zone: make([]Coord, 0), Cells: make([]ZoneCell, 0)}
 
-	seen := make([]Coord, 0)
+	turns := map[Coord]int{head: 0}
+	eaten := map[Coord]int{head: 0}
+	index := make(map[Coord]int)
 	q := Queue{}
 	q.Enqueue(head)
 
-	depthTrack := 1
-	countdownToNextDepth := 1
-
 	for !q.IsEmpty() {
 		curr, _ := q.Dequeue()
-
-		// depth bookkeeping
-		countdownToNextDepth--
-		if countdownToNextDepth == 0 {
-			depthTrack++
-		}
-
-		if depthTrack > depthLimit {
+		turn := turns[curr]
+		if turn >= depthLimit {
 			continue
 		}
-
-		if hasCoord(curr, seen) {
-			continue
+		ate := eaten[curr]
+		if curr != head && hasFood(curr, board.Food) {
+			ate++
 		}
-		if isOffBoard(curr, board) {
-			continue
+
+		for _, next := range makeNextMoves(curr) {
+			if t, seen := turns[next]; seen {
+				// another way in on the same turn, it may have eaten more on the way
+				if i, ok := index[next]; ok && t == turn+1 && ate > eaten[next] {
+					eaten[next] = ate
+					zone.Cells[i].Outcome = headToHead(you.Length, snake.Length+ate)
+				}
+				continue
+			}
+			// don't traverse over snake bodies still there when the head arrives
+			if !timed.freeAt(next, turn+1) {
+				continue
+			}
+			turns[next] = turn + 1
+			eaten[next] = ate
+			index[next] = len(zone.Cells)
+			zone.Zone = append(zone.Zone, next)
+			zone.Cells = append(zone.Cells, ZoneCell{Coord: next, Turn: turn + 1, Outcome: headToHead(you.Length, snake.Length+ate)})
+			q.Enqueue(next)
 		}
+	}
 
-		// don't traverse over snake body
-		if hasCoord(curr, body) {
-			continue
+	return zone
+}
+
+func headToHead(ours, theirs int) HeadToHead {
+	if ours > theirs {
+		return WeWin
+	}
+	if ours == theirs {
+		return BothDie
+	}
+	return WeLose
+}
+
+// Meets returns the zone cell if the snake's head can be on c after exactly
+// turn moves. Heads can double back, so any later turn of the same parity counts.
+func (z HeadZone) Meets(c Coord, turn int) (ZoneCell, bool) {
+	for _, cell := range z.Cells {
+		if cell.Coord == c && cell.Turn <= turn && (turn-cell.Turn)%2 == 0 {
+			return cell, true
 		}
+	}
+	return ZoneCell{}, false
+}
 
-		seen = append(seen, curr)
+// Fatal is true when the head-on kills us, ties included unless we're trading
+func (o HeadToHead) Fatal(trading bool) bool {
+	return o == WeLose || (o == BothDie && !trading)
+}
 
-		for _, d := range directionalMoves {
-			q.Enqueue(Coord{head.X + d.X, head.Y + d.Y})
-			countdownToNextDepth++
+// fatalHeadOn is true if any snake's head can meet us on c after turn moves and kill us
+func fatalHeadOn(c Coord, turn int, zones []HeadZone, trading bool) bool {
+	for _, z := range zones {
+		if cell, ok := z.Meets(c, turn); ok && cell.Outcome.Fatal(trading) {
+			return true
 		}
 	}
+	return false
+}
 
-	return HeadZone{SnakeHead: head, SnakeLength: length, SnakeName: name, Zone: seen}
+// tradingOnTies is true when we'd take a draw by meeting an equal snake head-on:
+// one on one and losing the fight for space anyway
+func tradingOnTies(state GameState) bool {
+	others := opponents(state)
+	if len(others) != 1 {
+		return false
+	}
+	territory := VoronoiTerritory(state.Board)
+	return territory.Of(state.You.ID).Cells*2 < territory.Of(others[0].ID).Cells
 }
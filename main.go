@@ -1,13 +1,18 @@
 package main
 
 import (
+	"fmt"
 	"log"
-	"math/rand"
-	"time"
+	"os"
 )
 
 var priorMoves = make(map[string]string)
 
+// gameKey identifies one of our snakes in one game, the same game can have more than one of them
+func gameKey(state GameState) string {
+	return state.Game.ID + "/" + state.You.ID
+}
+
 // info is called when you create your Battlesnake on play.battlesnake.com
 // and controls your Battlesnake's appearance
 // TIP: If you open your Battlesnake URL in a browser you should see this data
@@ -59,6 +64,55 @@ func infoVNext() BattlesnakeInfoResponse {
 		Version:    "0.0.1-beta",
 	}
 }
+func infoAggressive() BattlesnakeInfoResponse {
+	log.Println("Creating new battlesnake aggressive")
+
+	return BattlesnakeInfoResponse{
+		APIVersion: "1",
+		Author:     "Dave-Smith",
+		Color:      "#d92b2b",
+		Head:       "evil",
+		Tail:       "sharp",
+		Version:    "0.0.1-beta",
+	}
+}
+
+func infoWeighted() BattlesnakeInfoResponse {
+	log.Println("Creating new battlesnake weighted")
+
+	return BattlesnakeInfoResponse{
+		APIVersion: "1",
+		Author:     "Dave-Smith",
+		Color:      "#3d7ab8",
+		Head:       "all-seeing",
+		Tail:       "bolt",
+		Version:    "0.0.1-beta",
+	}
+}
+func infoChaser() BattlesnakeInfoResponse {
+	log.Println("Creating new battlesnake chaser")
+
+	return BattlesnakeInfoResponse{
+		APIVersion: "1",
+		Author:     "Dave-Smith",
+		Color:      "#b84a3d",
+		Head:       "all-seeing",
+		Tail:       "curled",
+		Version:    "0.0.1-beta",
+	}
+}
+func infoRules() BattlesnakeInfoResponse {
+	log.Println("Creating new battlesnake rules")
+
+	return BattlesnakeInfoResponse{
+		APIVersion: "1",
+		Author:     "Dave-Smith",
+		Color:      "#8a3db8",
+		Head:       "all-seeing",
+		Tail:       "do-sammy",
+		Version:    "0.0.1-beta",
+	}
+}
 
 // start is called when your Battlesnake begins a game
 func start(state GameState) {
@@ -68,6 +122,9 @@ func start(state GameState) {
 // end is called when your Battlesnake finishes a game
 func end(state GameState) {
 	log.Printf("[%s] GAME OVER\n\n", state.You.Name)
+	stopPondering(state)
+	forgetOpponents(state)
+	forgetTailChase(state)
 	log.Printf("[%s] Ending position: [%d,%d], Body: %v, ending health %d, ending length %d", state.You.Name, state.You.Head.X, state.You.Head.Y, state.You.Body, state.You.Health, state.You.Length)
 }
 
@@ -139,8 +196,8 @@ func move(state GameState) BattlesnakeMoveResponse {
 
 	// Are there any safe moves left?
 	safeMoves := []string{}
-	for move, isSafe := range isMoveSafe {
-		if isSafe {
+	for _, move := range []string{"up", "down", "left", "right"} {
+		if isMoveSafe[move] {
 			safeMoves = append(safeMoves, move)
 		}
 	}
@@ -157,7 +214,7 @@ func move(state GameState) BattlesnakeMoveResponse {
 	if val, ok := priorMoves[state.Game.ID]; len(safeMoves) > 1 && ok && isMoveSafe[val] {
 		nextMove = val
 	} else {
-		nextMove = safeMoves[rand.Intn(len(safeMoves))]
+		nextMove = safeMoves[gameRand(state).Intn(len(safeMoves))]
 	}
 
 	priorMoves[state.Game.ID] = nextMove
@@ -174,35 +231,68 @@ func move(state GameState) BattlesnakeMoveResponse {
 func moveSemiBlindWandering(state GameState) BattlesnakeMoveResponse {
 	curr := state.You.Head
 	gameMap := fillMap(state.Board, state.You)
-	safe := safeMoves(curr, gameMap)
+	safe := safeMoves(curr, gameMap, state.Board, state.You.Health, state.Game.Ruleset.Settings.HazardDamagePerTurn)
 	log.Printf("[%s] Safe coordinates for next move %v", state.You.Name, safe)
-	rand.Seed(time.Now().Unix())
-	next := safe[rand.Intn(len(safe))]
+	next := safe[gameRand(state).Intn(len(safe))]
 	return BattlesnakeMoveResponse{Move: dir(curr, next)}
 }
 
 func moveLessBlindWandering(state GameState) BattlesnakeMoveResponse {
 	curr := state.You.Head
-	gameMap := fillMap(state.Board, state.You)
-	opponents := make([]Battlesnake, 0)
-	for i := 0; i < len(state.Board.Snakes); i++ {
-		if state.You.ID != state.Board.Snakes[i].ID {
-			opponents = append(opponents, state.Board.Snakes[i])
+	timed := makeTimedMap(state.Board)
+	// back off to riskier moves until something survives: near other heads,
+	// then into dead ends
+	zones := headZonesFor(state, 1)
+	attempts := []struct {
+		name  string
+		depth int
+		zones []HeadZone
+	}{{"clear of heads", 4, zones}, {"near heads", 4, nil}, {"into dead ends", 1, nil}}
+	trace := newTrace()
+	var safe []Coord
+	decidedBy := ""
+	for _, a := range attempts {
+		safe = survivableSteps(state, saferMoves(curr, timed, make([]Coord, 0), a.depth, a.zones))
+		if len(safe) > 0 {
+			decidedBy = "random safe move " + a.name
+			break
 		}
 	}
-	safe := saferMoves(curr, gameMap, make([]Coord, 0), 4, opponents)
+	trace.Phase("safe moves")
+	if len(safe) == 0 {
+		log.Printf("[%s] MOVE %d: No safe moves detected! Moving up", state.You.Name, state.Turn)
+		return trace.Respond(BattlesnakeMoveResponse{Move: "up"}, "no safe moves")
+	}
+	// every safe move is as likely as the others
+	for _, c := range safe {
+		trace.Score(dir(curr, c), 1/float64(len(safe)), nil)
+	}
 	log.Printf("[%s] Safe coordinates for next move %v", state.You.Name, safe)
-	rand.Seed(time.Now().Unix())
-	next := safe[rand.Intn(len(safe))]
-	return BattlesnakeMoveResponse{Move: dir(curr, next)}
+	next := safe[gameRand(state).Intn(len(safe))]
+	return trace.Respond(BattlesnakeMoveResponse{Move: dir(curr, next)}, decidedBy)
 }
 
 func moveSmart(state GameState) BattlesnakeMoveResponse {
+	return moveSmartWith(state, defaultParams)
+}
+
+// moveSmartWith is moveSmart playing by the given thresholds
+func moveSmartWith(state GameState, p Params) BattlesnakeMoveResponse {
 	// scan the board for a possible moves
 	//myLength := state.You.Length
 	log.Printf("[%s] Starting Turn %d", state.You.Name, state.Turn)
-	possible := fillToDepth(state.You.Head, state.You.Length, state.Board)
+	trace := newTrace()
+	ctx, cancel := searchContext(state)
+	defer cancel()
+	possible := searchMoves(ctx, state)
+	trace.Phase("search")
+	trace.Consider(possible)
 	possible = possible.avoidCertainDeath()
+	trace.Keep(possible, "certain death")
+	if len(possible) == 0 {
+		log.Printf("[%s] MOVE %d: No safe moves detected! Moving up", state.You.Name, state.Turn)
+		return trace.Respond(BattlesnakeMoveResponse{Move: "up"}, "no safe moves")
+	}
 
 	var bestMove WeightedMovement
 
@@ -217,78 +307,91 @@ func moveSmart(state GameState) BattlesnakeMoveResponse {
 		}
 	}
 
-	dangerishZones := MakeHeadZones(otherSnakes, state.Board, 2)
+	// the tail chase looks two moves out, whatever depth we play by
+	depth := p.HeadZoneDepth
+	if depth < tailChaseZoneDepth {
+		depth = tailChaseZoneDepth
+	}
+	zones := headZonesFor(state, depth)
+	weighHeadZones(state, zones)
+	dangerishZones := headZonesWithin(zones, p.HeadZoneDepth)
 	log.Printf("Other snakes bubbles %v", dangerishZones)
+	log.Printf("[%s] Territory %s", state.You.Name, VoronoiTerritory(state.Board))
+
+	// equal heads kill us both, only risk it when we mean to
+	trading := tradingOnTies(state)
+	possible = possible.avoidHeadOn(dangerishZones, trading)
+	trace.Keep(possible, "losing head-on")
+	possible = possible.avoidSmallChambers(state)
+	trace.Keep(possible, "small chamber")
+	trace.Phase("head zones and chambers")
+	for _, m := range possible {
+		trace.Score(m.movement.asString(), float64(len(m.open)), map[string]float64{
+			"open":     float64(len(m.open)),
+			"food":     float64(m.distanceToFood),
+			"opponent": float64(m.nearestOpponent.distance),
+		})
+	}
 
-	// possible offensive attack
-	for _, p := range possible {
+	// possible offensive attack, go where the smaller snake is most likely headed
+	attack := -1
+	attackOdds := -1.0
+	for i, p := range possible {
 		for _, danger := range dangerishZones {
-			if hasCoord(p.root, danger.Zone) && danger.SnakeLength < state.You.Length {
-				return BattlesnakeMoveResponse{Move: p.movement.asString()}
+			if cell, ok := danger.Meets(p.root, 1); ok && cell.Outcome == WeWin && danger.Odds[p.root] > attackOdds {
+				attack = i
+				attackOdds = danger.Odds[p.root]
 			}
 		}
 	}
+	if attack >= 0 {
+		return trace.Respond(BattlesnakeMoveResponse{Move: possible[attack].movement.asString()}, fmt.Sprintf("attack, %.2f odds the smaller head moves there", attackOdds))
+	}
 
-	// possibleMoves := make([]PossibleMove, 0)
-	// for _, p := range possible {
-	// 	curr := state.You.Head
-	// 	next := p.root
-	// 	move := PossibleMove{
-	// 		Curr:                     curr,
-	// 		Next:                     p.root,
-	// 		Prev:                     state.You.Body[1],
-	// 		Dir:                      p.movement,
-	// 		IsOffBoard:               isOffBoard(next, state.Board),
-	// 		IsBackwards:              next == state.You.Body[1],
-	// 		IsCorner:                 isCorner(next, state.Board),
-	// 		IsOnBorder:               isOnBorder(next, state.Board),
-	// 		IsOccupied:               isOccupied(next, state.Board.Snakes),
-	// 		IsOccupiedBySmallerSnake: isOccupiedBySmallerSnake(next, state.You, otherSnakes),
-	// 		HasFood:                  hasFood(next, state.Board.Food),
-	// 		IsNearAnySnake:           isNearSnakeHead(next, dangerishZones),
-	// 		IsNearSmallerSnake:       isNearSmallerSnakeHead(next, state.You, dangerishZones),
-	// 		NearestSmallerSnake:      nearestSmallerSnake(next, state.You, otherSnakes),
-	// 	}
-	// 	possibleMoves = append(possibleMoves, move)
-	// }
+	possibleMoves := FindNextMoves(state.You, otherSnakes, state.Board.Food, state.Board, state.Game.Ruleset.Settings.HazardDamagePerTurn, zones)
+	log.Printf("[%s] Possible moves %+v", state.You.Name, possibleMoves)
 
 	// start game hunting for food
-	if state.Turn < 100 || state.You.Health < 50 || state.You.Length < longestSnake {
-		food := NearestFoods(state.You, state.Board)
-		if len(food) > 0 {
-			for _, f := range food {
-				isSafe := false
-				movement := UseMovement(state.You.Head, f.Coords[0])
-				for _, p := range possible {
-					if p.movement == movement && !isCorner(p.root, state.Board) {
-						isSafe = true
-					}
-				}
-				for _, danger := range dangerishZones {
-					if !isSafe {
-						break
-					}
-					if hasCoord(f.Coords[0], danger.Zone) {
-						isSafe = false
-					}
-				}
-				if isSafe {
-					return BattlesnakeMoveResponse{Move: movement.asString()}
+	if state.Turn < p.EarlyGameTurns || state.You.Health < p.HungryHealth || state.You.Length < longestSnake {
+		food := FindFood(state.You, otherSnakes, state.Board, state.Game.Ruleset.Settings.HazardDamagePerTurn)
+		for _, f := range food {
+			if !f.Winnable() || f.Path.Len() == 0 {
+				continue
+			}
+			isSafe := false
+			movement := UseMovement(state.You.Head, f.Path.Coords[0])
+			for _, p := range possible {
+				if p.movement == movement && !isCorner(p.root, state.Board) {
+					isSafe = true
 				}
 			}
+			if isSafe && fatalHeadOn(f.Path.Coords[0], 1, dangerishZones, trading) {
+				isSafe = false
+			}
+			if isSafe {
+				log.Printf("[%s] Going for food at %v, %d moves away, nearest rival %d away", state.You.Name, f.Location, f.DistToMe, f.DistToSnake)
+				return trace.Respond(BattlesnakeMoveResponse{Move: movement.asString()}, fmt.Sprintf("food hunt, food at %v %d moves away", f.Location, f.DistToMe))
+			}
 		}
 	}
 
-	// if board has more than 7 snakes, stay small
-	if len(state.Board.Snakes) > 7 {
-		if state.You.Health < 30 {
+	trace.Phase("food")
+
+	// boxed in but healthy, keep circling after our tail
+	if next, ok := chaseTail(state, p, zones, nil); ok {
+		return trace.Respond(BattlesnakeMoveResponse{Move: dir(state.You.Head, next), Shout: "Chasing my tail"}, "tail chase")
+	}
+
+	// if the board is crowded, stay small
+	if len(state.Board.Snakes) > p.CrowdedSnakes {
+		if state.You.Health < p.CrowdedHungryHealth {
 			bestMove = possible.bestMoveForFood(state.You)
 		}
-		if state.You.Health > 30 {
+		if state.You.Health > p.CrowdedHungryHealth {
 			bestMove = possible.bestMoveToAvoidFood(state.You)
 		}
 		bestMove = possible.bestMoveForRoaming(state.You)
-		return BattlesnakeMoveResponse{Move: bestMove.movement.asString()}
+		return trace.Respond(BattlesnakeMoveResponse{Move: bestMove.movement.asString()}, "roaming on a crowded board")
 	}
 
 	// if 3 snakes, find food
@@ -306,18 +409,16 @@ func moveSmart(state GameState) BattlesnakeMoveResponse {
 	//}
 	bestMove = defensiveMove
 
-	if state.You.Health < 45 {
+	decidedBy := "roaming"
+	if state.You.Health < p.StarvingHealth {
 		bestMove = possible.bestMoveForFood(state.You)
 		log.Printf("[%s] looking for food, %d moves away", state.You.Name, bestMove.distanceToFood)
+		decidedBy = "starving, closest food"
 	} else {
 		bestMove = defensiveMove
 	}
 
-	return BattlesnakeMoveResponse{Move: bestMove.movement.asString(), Shout: "Avoiding food"}
-}
-
-func moveAggressive(state GameState) BattlesnakeMoveResponse {
-	return BattlesnakeMoveResponse{Move: "up", Shout: "I'm coming after you"}
+	return trace.Respond(BattlesnakeMoveResponse{Move: bestMove.movement.asString(), Shout: "Avoiding food"}, decidedBy)
 }
 
 func movePassive(state GameState) BattlesnakeMoveResponse {
@@ -325,5 +426,8 @@ func movePassive(state GameState) BattlesnakeMoveResponse {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1], os.Args[2:])
+	}
 	RunServer()
 }
@@ -45,6 +45,8 @@ type GameState struct {
 	Turn  int         `json:"turn"`
 	Board Board       `json:"board"`
 	You   Battlesnake `json:"you"`
+	// Seed overrides the game ID as the source of randomness, for simulations
+	Seed int64 `json:"-"`
 }
 
 type Game struct {
@@ -82,4 +84,6 @@ type BattlesnakeInfoResponse struct {
 type BattlesnakeMoveResponse struct {
 	Move  string `json:"move"`
 	Shout string `json:"shout"`
+	// how the move was picked, never sent to the game
+	Trace *Trace `json:"-"`
 }
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+)
+
+// OpponentSpace is the room an opponent has left after one of our moves
+type OpponentSpace struct {
+	SnakeID     string
+	SnakeName   string
+	Length      int
+	Space       int
+	Trapped     bool
+	CutFromFood bool
+}
+
+// CutoffAnalysis is what one of our moves does to the room everyone has
+type CutoffAnalysis struct {
+	Movement  Movement
+	Root      Coord
+	OurSpace  int
+	Opponents []OpponentSpace
+}
+
+// AnalyseCutoffs works out, for each move, the space each opponent has left
+// afterwards. An opponent with less room than its length can't survive, and
+// one with no food in its room will starve.
+func AnalyseCutoffs(state GameState, moves WeightedMovementSet) []CutoffAnalysis {
+	analyses := make([]CutoffAnalysis, 0, len(moves))
+	for _, m := range moves {
+		analyses = append(analyses, analyseCutoff(state, m))
+	}
+	return analyses
+}
+
+func analyseCutoff(state GameState, move WeightedMovement) CutoffAnalysis {
+	damage := state.Game.Ruleset.Settings.HazardDamagePerTurn
+	board := applyMove(state.Board, state.You.ID, move.root, damage)
+	you, ok := findSnake(state.You.ID, board.Snakes)
+	if !ok {
+		you = state.You
+	}
+	analysis := CutoffAnalysis{
+		Movement:  move.movement,
+		Root:      move.root,
+		OurSpace:  len(reachableWithHealth(move.root, board, you.Health, damage)),
+		Opponents: make([]OpponentSpace, 0, len(board.Snakes)),
+	}
+
+	for _, s := range board.Snakes {
+		if s.ID == state.You.ID {
+			continue
+		}
+		room := reachableWithHealth(s.Head, board, s.Health, damage)
+		hasFood := false
+		for _, f := range board.Food {
+			if hasCoord(f, room) {
+				hasFood = true
+				break
+			}
+		}
+		analysis.Opponents = append(analysis.Opponents, OpponentSpace{
+			SnakeID:     s.ID,
+			SnakeName:   s.Name,
+			Length:      s.Length,
+			Space:       len(room),
+			Trapped:     len(room) < s.Length,
+			CutFromFood: len(board.Food) > 0 && !hasFood,
+		})
+	}
+	return analysis
+}
+
+// Kills counts the opponents the move leaves without enough room to survive
+func (c CutoffAnalysis) Kills() int {
+	kills := 0
+	for _, o := range c.Opponents {
+		if o.Trapped {
+			kills++
+		}
+	}
+	return kills
+}
+
+// FoodCutoffs counts the opponents the move leaves with no food in reach
+func (c CutoffAnalysis) FoodCutoffs() int {
+	cut := 0
+	for _, o := range c.Opponents {
+		if o.CutFromFood {
+			cut++
+		}
+	}
+	return cut
+}
+
+func (c CutoffAnalysis) OpponentSpace() int {
+	space := 0
+	for _, o := range c.Opponents {
+		space += o.Space
+	}
+	return space
+}
+
+func (c CutoffAnalysis) String() string {
+	return fmt.Sprintf("%s: our space %d, kills %d, food cutoffs %d, opponent space %d",
+		c.Movement.asString(), c.OurSpace, c.Kills(), c.FoodCutoffs(), c.OpponentSpace())
+}
+
+// cutoffFeature rewards moves that trap opponents or cut them off from food
+func cutoffFeature(state GameState, move WeightedMovement) float64 {
+	analysis := analyseCutoff(state, move)
+	return float64(analysis.Kills()) + 0.5*float64(analysis.FoodCutoffs())
+}
+
+// moveAggressive goes for the move that boxes in the most opponents without boxing in itself
+func moveAggressive(state GameState) BattlesnakeMoveResponse {
+	trace := newTrace()
+	ctx, cancel := searchContext(state)
+	defer cancel()
+	all := searchMoves(ctx, state)
+	trace.Phase("search")
+	trace.Consider(all)
+	possible := append(WeightedMovementSet{}, all...).avoidCertainDeath()
+	trace.Keep(possible, "certain death")
+	if len(possible) == 0 {
+		log.Printf("[%s] No moves avoid certain death", state.You.Name)
+		return trace.Respond(BattlesnakeMoveResponse{Move: all[0].movement.asString(), Shout: "I'm coming after you"}, "no safe moves")
+	}
+	zones := headZonesFor(state, 1)
+	possible = possible.avoidHeadOn(zones, tradingOnTies(state))
+	trace.Keep(possible, "losing head-on")
+
+	analyses := AnalyseCutoffs(state, possible)
+	for _, a := range analyses {
+		log.Printf("[%s] Cutoff %s", state.You.Name, a)
+		trace.Score(a.Movement.asString(), float64(a.Kills()), map[string]float64{
+			"kills":         float64(a.Kills()),
+			"foodCutoffs":   float64(a.FoodCutoffs()),
+			"opponentSpace": float64(a.OpponentSpace()),
+			"ourSpace":      float64(a.OurSpace),
+		})
+	}
+	trace.Phase("cutoffs")
+	sort.SliceStable(analyses, func(i, j int) bool {
+		a, b := analyses[i], analyses[j]
+		if safeA, safeB := a.OurSpace >= state.You.Length, b.OurSpace >= state.You.Length; safeA != safeB {
+			return safeA
+		}
+		if a.Kills() != b.Kills() {
+			return a.Kills() > b.Kills()
+		}
+		if a.FoodCutoffs() != b.FoodCutoffs() {
+			return a.FoodCutoffs() > b.FoodCutoffs()
+		}
+		if a.OpponentSpace() != b.OpponentSpace() {
+			return a.OpponentSpace() < b.OpponentSpace()
+		}
+		return a.OurSpace > b.OurSpace
+	})
+
+	return trace.Respond(BattlesnakeMoveResponse{Move: analyses[0].Movement.asString(), Shout: "I'm coming after you"}, "cutoffs, most kills then least opponent space")
+}
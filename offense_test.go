@@ -0,0 +1,70 @@
+package main
+
+import "testing"
+
+func cutoffState(t *testing.T, ascii string, health int) GameState {
+	board, err := ParseASCIIBoard(ascii)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range board.Snakes {
+		if board.Snakes[i].ID == "A" {
+			board.Snakes[i].Health = health
+		}
+	}
+	you, _ := findSnake("A", board.Snakes)
+	return GameState{Game: Game{ID: t.Name()}, Board: board, You: you}
+}
+
+func cutoffFor(analyses []CutoffAnalysis, movement Movement) CutoffAnalysis {
+	for _, a := range analyses {
+		if a.Movement == movement {
+			return a
+		}
+	}
+	return CutoffAnalysis{}
+}
+
+func TestAnalyseCutoffs(t *testing.T) {
+	// going left seals B into the corner, away from the food
+	state := cutoffState(t, `
+		Baa.*
+		baa..
+		.A...
+	`, 90)
+	moves := fillToDepth(state.You.Head, state.You.Length, state.Board).avoidCertainDeath()
+	analyses := AnalyseCutoffs(state, moves)
+
+	left := cutoffFor(analyses, Left)
+	if left.Kills() != 1 || left.FoodCutoffs() != 1 {
+		t.Errorf("left = %s, want B trapped and cut from food", left)
+	}
+	right := cutoffFor(analyses, Right)
+	if right.Kills() != 0 {
+		t.Errorf("right = %s, B can still get out", right)
+	}
+}
+
+func TestAnalyseCutoffEats(t *testing.T) {
+	// on 1 health only the food keeps us going
+	state := cutoffState(t, `
+		.....
+		.*Aa.
+		.....
+	`, 1)
+	moves := fillToDepth(state.You.Head, state.You.Length, state.Board)
+	var left WeightedMovement
+	for _, m := range moves {
+		if m.movement == Left {
+			left = m
+		}
+	}
+	board := applyMove(state.Board, state.You.ID, left.root, 0)
+	you, _ := findSnake(state.You.ID, board.Snakes)
+	if len(board.Food) != 0 || you.Length != 3 || you.Health != maxHealth {
+		t.Errorf("after eating food %v, A = length %d health %d", board.Food, you.Length, you.Health)
+	}
+	if analysis := analyseCutoff(state, left); analysis.OurSpace < 10 {
+		t.Errorf("our space after eating = %d, want the open board", analysis.OurSpace)
+	}
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"log"
+	"sort"
+	"sync"
+)
+
+// OpponentTendencies counts how often an opponent did something when it had the choice
+type OpponentTendencies struct {
+	Observed        int
+	TowardFood      int
+	FoodChances     int
+	AwayFromWall    int
+	WallChances     int
+	TowardHead      int
+	HeadChances     int
+	Straight        int
+	StraightChances int
+}
+
+type gameModel struct {
+	last      GameState
+	opponents map[string]*OpponentTendencies
+}
+
+type opponentModeler struct {
+	mu    sync.Mutex
+	games map[string]*gameModel
+}
+
+var opponentModels = opponentModeler{games: make(map[string]*gameModel)}
+
+// rate smooths a count so a snake we know nothing about is a coin flip
+func rate(count, chances int) float64 {
+	return float64(count+1) / float64(chances+2)
+}
+
+func (t OpponentTendencies) FoodRate() float64     { return rate(t.TowardFood, t.FoodChances) }
+func (t OpponentTendencies) WallRate() float64     { return rate(t.AwayFromWall, t.WallChances) }
+func (t OpponentTendencies) HeadRate() float64     { return rate(t.TowardHead, t.HeadChances) }
+func (t OpponentTendencies) StraightRate() float64 { return rate(t.Straight, t.StraightChances) }
+
+// observeOpponents infers every opponent's last move by diffing against the
+// previous turn of the same game and updates its tendencies
+func observeOpponents(state GameState) {
+	opponentModels.mu.Lock()
+	defer opponentModels.mu.Unlock()
+
+	key := gameKey(state)
+	model, ok := opponentModels.games[key]
+	if !ok {
+		model = &gameModel{opponents: make(map[string]*OpponentTendencies)}
+		opponentModels.games[key] = model
+	}
+	defer func() { model.last = state }()
+
+	if !ok || model.last.Turn != state.Turn-1 {
+		return
+	}
+
+	for _, snake := range state.Board.Snakes {
+		if snake.ID == state.You.ID {
+			continue
+		}
+		prev, found := findSnake(snake.ID, model.last.Board.Snakes)
+		if !found || distanceTo(prev.Head, snake.Head) != 1 {
+			continue
+		}
+		tendencies, ok := model.opponents[snake.ID]
+		if !ok {
+			tendencies = &OpponentTendencies{}
+			model.opponents[snake.ID] = tendencies
+		}
+		tendencies.observe(prev, snake.Head, model.last.Board)
+	}
+}
+
+func (t *OpponentTendencies) observe(prev Battlesnake, next Coord, board Board) {
+	t.Observed++
+	choices := likelyMoves(prev, board)
+	if len(choices) < 2 {
+		// no choice, nothing learned
+		return
+	}
+
+	traits := moveTraits(prev, board, choices)
+	chosen := traits[next]
+	if traits.varies(func(m moveTrait) bool { return m.towardFood }) {
+		t.FoodChances++
+		if chosen.towardFood {
+			t.TowardFood++
+		}
+	}
+	if traits.varies(func(m moveTrait) bool { return m.awayFromWall }) {
+		t.WallChances++
+		if chosen.awayFromWall {
+			t.AwayFromWall++
+		}
+	}
+	if traits.varies(func(m moveTrait) bool { return m.towardHead }) {
+		t.HeadChances++
+		if chosen.towardHead {
+			t.TowardHead++
+		}
+	}
+	if traits.varies(func(m moveTrait) bool { return m.straight }) {
+		t.StraightChances++
+		if chosen.straight {
+			t.Straight++
+		}
+	}
+}
+
+type moveTrait struct {
+	towardFood   bool
+	awayFromWall bool
+	towardHead   bool
+	straight     bool
+}
+
+type moveTraitSet map[Coord]moveTrait
+
+func (traits moveTraitSet) varies(has func(moveTrait) bool) bool {
+	yes, no := false, false
+	for _, t := range traits {
+		if has(t) {
+			yes = true
+		} else {
+			no = true
+		}
+	}
+	return yes && no
+}
+
+// moveTraits describes each of a snake's choices from where it stands
+func moveTraits(snake Battlesnake, board Board, choices []Coord) moveTraitSet {
+	food := nearest(snake.Head, board.Food)
+	heads := make([]Coord, 0, len(board.Snakes))
+	for _, s := range board.Snakes {
+		if s.ID != snake.ID {
+			heads = append(heads, s.Head)
+		}
+	}
+	head := nearest(snake.Head, heads)
+
+	other := describeSnake(snake, board, makeTimedMap(board))
+	traits := make(moveTraitSet, len(choices))
+	for _, c := range choices {
+		traits[c] = moveTrait{
+			towardFood:   len(board.Food) > 0 && distanceTo(c, food) < distanceTo(snake.Head, food),
+			awayFromWall: !isOnBorder(c, board),
+			towardHead:   len(heads) > 0 && distanceTo(c, head) < distanceTo(snake.Head, head),
+			straight:     other.HasMoved() && moveCoord(other.Head, other.PrevMove) == c,
+		}
+	}
+	return traits
+}
+
+// OpponentMoveProbabilities is the chance of the snake's head landing on each
+// legal cell next turn, weighed by what it has done so far this game
+func OpponentMoveProbabilities(state GameState, snake Battlesnake) map[Coord]float64 {
+	choices := likelyMoves(snake, state.Board)
+	odds := make(map[Coord]float64, len(choices))
+	if len(choices) == 0 {
+		return odds
+	}
+
+	tendencies := opponentTendencies(state, snake.ID)
+	traits := moveTraits(snake, state.Board, choices)
+	total := 0.0
+	for _, c := range choices {
+		t := traits[c]
+		w := 1.0
+		if traits.varies(func(m moveTrait) bool { return m.towardFood }) {
+			w *= weigh(t.towardFood, tendencies.FoodRate())
+		}
+		if traits.varies(func(m moveTrait) bool { return m.awayFromWall }) {
+			w *= weigh(t.awayFromWall, tendencies.WallRate())
+		}
+		if traits.varies(func(m moveTrait) bool { return m.towardHead }) {
+			w *= weigh(t.towardHead, tendencies.HeadRate())
+		}
+		if traits.varies(func(m moveTrait) bool { return m.straight }) {
+			w *= weigh(t.straight, tendencies.StraightRate())
+		}
+		odds[c] = w
+		total += w
+	}
+	for c := range odds {
+		odds[c] /= total
+	}
+	return odds
+}
+
+func weigh(has bool, rate float64) float64 {
+	if has {
+		return rate
+	}
+	return 1 - rate
+}
+
+// likelyMovesByOdds orders a snake's legal next heads, most probable first
+func likelyMovesByOdds(state GameState, snake Battlesnake) []Coord {
+	moves := likelyMoves(snake, state.Board)
+	odds := OpponentMoveProbabilities(state, snake)
+	sort.SliceStable(moves, func(i, j int) bool {
+		return odds[moves[i]] > odds[moves[j]]
+	})
+	return moves
+}
+
+func opponentTendencies(state GameState, snakeID string) OpponentTendencies {
+	opponentModels.mu.Lock()
+	defer opponentModels.mu.Unlock()
+	if model, ok := opponentModels.games[gameKey(state)]; ok {
+		if t, ok := model.opponents[snakeID]; ok {
+			return *t
+		}
+	}
+	return OpponentTendencies{}
+}
+
+// forgetOpponents drops the game's model once it's over
+func forgetOpponents(state GameState) {
+	opponentModels.mu.Lock()
+	defer opponentModels.mu.Unlock()
+	if model, ok := opponentModels.games[gameKey(state)]; ok {
+		for id, t := range model.opponents {
+			log.Printf("[%s] Opponent %s tendencies %+v", state.You.Name, id, *t)
+		}
+	}
+	delete(opponentModels.games, gameKey(state))
+}
+
+func findSnake(id string, snakes []Battlesnake) (Battlesnake, bool) {
+	for _, s := range snakes {
+		if s.ID == id {
+			return s, true
+		}
+	}
+	return Battlesnake{}, false
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// B can go for the food straight ahead or turn away from it
+const foodAheadBoard = `
+	.....
+	..*..
+	..B..
+	..b..
+	..b..
+`
+
+func TestObserveCountsChoices(t *testing.T) {
+	board, err := ParseASCIIBoard(foodAheadBoard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := findSnake("B", board.Snakes)
+
+	var tendencies OpponentTendencies
+	tendencies.observe(b, Coord{2, 3}, board)
+	tendencies.observe(b, Coord{1, 2}, board)
+	want := OpponentTendencies{Observed: 2, TowardFood: 1, FoodChances: 2, Straight: 1, StraightChances: 2}
+	if tendencies != want {
+		t.Errorf("tendencies = %+v, want %+v", tendencies, want)
+	}
+
+	// boxed in, nothing to learn
+	boxed, err := ParseASCIIBoard(`
+		Bb
+		.b
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ = findSnake("B", boxed.Snakes)
+	tendencies = OpponentTendencies{}
+	tendencies.observe(b, Coord{0, 0}, boxed)
+	if tendencies != (OpponentTendencies{Observed: 1}) {
+		t.Errorf("learned %+v from a forced move", tendencies)
+	}
+}
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		count, chances int
+		want           float64
+	}{
+		{0, 0, 0.5},
+		{3, 4, 4.0 / 6},
+		{0, 8, 0.1},
+		{8, 8, 0.9},
+	}
+	for _, tt := range tests {
+		if got := rate(tt.count, tt.chances); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("rate(%d, %d) = %v, want %v", tt.count, tt.chances, got, tt.want)
+		}
+	}
+}
+
+func TestOpponentMoveProbabilities(t *testing.T) {
+	board, err := ParseASCIIBoard(foodAheadBoard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := findSnake("B", board.Snakes)
+	state := GameState{Game: Game{ID: t.Name()}, Turn: 1, Board: board, You: Battlesnake{ID: "you"}}
+	defer forgetOpponents(state)
+
+	check := func() map[Coord]float64 {
+		odds := OpponentMoveProbabilities(state, b)
+		legal := likelyMoves(b, state.Board)
+		if len(odds) != len(legal) {
+			t.Fatalf("odds %v over %d legal moves", odds, len(legal))
+		}
+		total := 0.0
+		for _, c := range legal {
+			total += odds[c]
+		}
+		if math.Abs(total-1) > 1e-9 {
+			t.Errorf("odds %v sum to %v", odds, total)
+		}
+		return odds
+	}
+
+	check()
+
+	// B keeps turning away from food
+	moved := board
+	moved.Snakes = []Battlesnake{{ID: b.ID, Name: b.Name, Head: Coord{1, 2}, Body: []Coord{{1, 2}, {2, 2}, {2, 1}}, Length: 3}}
+	for turn := 1; turn < 12; turn += 3 {
+		observeOpponents(GameState{Game: state.Game, Turn: turn, Board: board, You: state.You})
+		observeOpponents(GameState{Game: state.Game, Turn: turn + 1, Board: moved, You: state.You})
+	}
+	if tendencies := opponentTendencies(state, b.ID); tendencies.Observed != 4 || tendencies.FoodChances != 4 || tendencies.TowardFood != 0 {
+		t.Fatalf("tendencies %+v after turning away from food 4 times", tendencies)
+	}
+	odds := check()
+	if odds[Coord{2, 3}] >= odds[Coord{1, 2}] {
+		t.Errorf("food shouldn't be likeliest for a snake that avoids it, odds %v", odds)
+	}
+}
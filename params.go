@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+)
+
+// Params are the thresholds moveSmart plays by
+type Params struct {
+	// hunt food until this turn
+	EarlyGameTurns int `json:"earlyGameTurns"`
+	// hunt food below this health
+	HungryHealth int `json:"hungryHealth"`
+	// roam toward food below this health
+	StarvingHealth int `json:"starvingHealth"`
+	// stay small with more than this many snakes on the board
+	CrowdedSnakes int `json:"crowdedSnakes"`
+	// on a crowded board, only eat below this health
+	CrowdedHungryHealth int `json:"crowdedHungryHealth"`
+	// how many moves ahead other heads are dangerous
+	HeadZoneDepth int `json:"headZoneDepth"`
+	// health we want before settling into chasing our tail
+	TailChaseEnterHealth int `json:"tailChaseEnterHealth"`
+	// health at which we give up the loop and go looking for food
+	TailChaseExitHealth int `json:"tailChaseExitHealth"`
+	// space is tight when we can reach fewer than this many cells per body segment
+	TailChaseSpaceFactor int `json:"tailChaseSpaceFactor"`
+}
+
+var defaultParams = Params{
+	EarlyGameTurns:       100,
+	HungryHealth:         50,
+	StarvingHealth:       45,
+	CrowdedSnakes:        7,
+	CrowdedHungryHealth:  30,
+	HeadZoneDepth:        2,
+	TailChaseEnterHealth: 60,
+	TailChaseExitHealth:  35,
+	TailChaseSpaceFactor: 3,
+}
+
+// paramRange bounds one tunable parameter
+type paramRange struct {
+	Name     string
+	Min, Max int
+}
+
+// paramRanges lines up with Params.genes
+var paramRanges = []paramRange{
+	{"earlyGameTurns", 0, 300},
+	{"hungryHealth", 1, 100},
+	{"starvingHealth", 1, 100},
+	{"crowdedSnakes", 1, 16},
+	{"crowdedHungryHealth", 1, 100},
+	{"headZoneDepth", 1, 4},
+	{"tailChaseEnterHealth", 1, 100},
+	{"tailChaseExitHealth", 1, 100},
+	{"tailChaseSpaceFactor", 1, 6},
+}
+
+// genes points at every tunable parameter, in paramRanges order
+func (p *Params) genes() []*int {
+	return []*int{
+		&p.EarlyGameTurns,
+		&p.HungryHealth,
+		&p.StarvingHealth,
+		&p.CrowdedSnakes,
+		&p.CrowdedHungryHealth,
+		&p.HeadZoneDepth,
+		&p.TailChaseEnterHealth,
+		&p.TailChaseExitHealth,
+		&p.TailChaseSpaceFactor,
+	}
+}
+
+// clamp keeps every parameter within its range
+func (p *Params) clamp() {
+	for i, g := range p.genes() {
+		r := paramRanges[i]
+		if *g < r.Min {
+			*g = r.Min
+		}
+		if *g > r.Max {
+			*g = r.Max
+		}
+	}
+}
+
+// LoadParams reads parameters from a json file, anything missing keeps its default
+func LoadParams(path string) (Params, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Params{}, err
+	}
+	params := defaultParams
+	if err := json.Unmarshal(data, &params); err != nil {
+		return Params{}, fmt.Errorf("parsing params %s: %w", path, err)
+	}
+	params.clamp()
+	return params, nil
+}
+
+// loadParamsFromEnv uses PARAMS_FILE if set, otherwise the default parameters
+func loadParamsFromEnv() Params {
+	path := os.Getenv("PARAMS_FILE")
+	if len(path) == 0 {
+		return defaultParams
+	}
+	params, err := LoadParams(path)
+	if err != nil {
+		log.Printf("ERROR: Failed to load params, using defaults, %s", err)
+		return defaultParams
+	}
+	log.Printf("Loaded params %+v from %s", params, path)
+	return params
+}
+
+// SmartMover plays moveSmart with the given parameters
+func SmartMover(p Params) SnakeMoverFunc {
+	return func(state GameState) BattlesnakeMoveResponse {
+		return moveSmartWith(state, p)
+	}
+}
+
+// TailChaserMover plays moveTailChaser with the given parameters
+func TailChaserMover(p Params) SnakeMoverFunc {
+	return func(state GameState) BattlesnakeMoveResponse {
+		return moveTailChaserWith(state, p)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"container/heap"
 	"sort"
 )
 
@@ -9,33 +10,71 @@ type Path struct {
 	Target    Coord
 	Coords    []Coord
 	Collision bool
+	Cost      int
+	// health lost walking the path, negative if we eat on the way
+	HealthCost int
 }
 
+// CellCost is the extra cost of stepping onto c after turn moves, ok is false
+// when the cell can't be entered then
+type CellCost func(c Coord, turn int) (cost int, ok bool)
+
+// extra cost of a cell where a snake at least our size could meet us head-on
+const headZoneStepCost = 3
+
 type byDistance []Path
 
 func (p Path) Len() int {
 	return len(p.Coords)
 }
 
-func AllFood(you Battlesnake, board Board) []Path {
+// AllFood returns the cheapest path to every food we can reach alive
+func AllFood(you Battlesnake, board Board, hazardDamage int) []Path {
 	var paths = make([]Path, 0)
 
+	cost := foodPathCost(you, board, hazardDamage)
 	for _, v := range board.Food {
-		toFood := MakePaths(you.Head, v, board)
-		paths = append(paths, toFood...)
+		if toFood, ok := FindSurvivablePath(you.Head, v, board, you.Health, hazardDamage, cost); ok {
+			paths = append(paths, toFood)
+		}
 	}
 	return paths
 }
 
-func NearestFoods(you Battlesnake, board Board) []Path {
-	var foods = AllFood(you, board)
+// foodPathCost avoids bodies until they've moved on, counts hazards as the
+// health they cost, and stays away from heads that can beat us
+func foodPathCost(you Battlesnake, board Board, hazardDamage int) CellCost {
+	timed := makeTimedMap(board)
+	others := make([]Battlesnake, 0, len(board.Snakes))
+	for _, s := range board.Snakes {
+		if s.ID != you.ID {
+			others = append(others, s)
+		}
+	}
+	zones := MakeHeadZones(FindOtherSnakes(others, board), you, board, 2)
+	stacks := hazardStacks(board)
+
+	return func(c Coord, turn int) (int, bool) {
+		if !timed.freeAt(c, turn) {
+			return 0, false
+		}
+		cost := stacks[c] * hazardDamage
+		if fatalHeadOn(c, turn, zones, false) {
+			cost += headZoneStepCost
+		}
+		return cost, true
+	}
+}
+
+func NearestFoods(you Battlesnake, board Board, hazardDamage int) []Path {
+	var foods = AllFood(you, board, hazardDamage)
 
 	sort.Sort(byDistance(foods))
 	return foods
 }
 
-func NearestFood(you Battlesnake, board Board) Path {
-	nearest := NearestFoods(you, board)
+func NearestFood(you Battlesnake, board Board, hazardDamage int) Path {
+	nearest := NearestFoods(you, board, hazardDamage)
 	if len(nearest) > 0 {
 		return nearest[0]
 	}
@@ -51,6 +90,9 @@ func (v byDistance) Swap(i, j int) {
 }
 
 func (v byDistance) Less(i, j int) bool {
+	if v[i].Cost != v[j].Cost {
+		return v[i].Cost < v[j].Cost
+	}
 	return v[i].Len() < v[j].Len()
 }
 
@@ -108,3 +150,108 @@ func MakePaths(source, target Coord, board Board) []Path {
 
 	return paths
 }
+
+// FindPath finds the cheapest path from source to target with A*. Every step
+// costs 1 plus whatever cost adds for the cell.
+func FindPath(source, target Coord, board Board, cost CellCost) (Path, bool) {
+	if isOffBoard(target, board) {
+		return Path{}, false
+	}
+
+	costs := map[Coord]int{source: 0}
+	turns := map[Coord]int{source: 0}
+	from := make(map[Coord]Coord)
+	open := &pathQueue{}
+	heap.Push(open, pathNode{coord: source, priority: distanceTo(source, target)})
+
+	for open.Len() > 0 {
+		node := heap.Pop(open).(pathNode)
+		curr := node.coord
+		if curr == target {
+			return Path{Source: source, Target: target, Coords: walkBack(source, target, from), Cost: costs[curr]}, true
+		}
+		if node.priority > costs[curr]+distanceTo(curr, target) {
+			// stale entry, already found a cheaper way here
+			continue
+		}
+
+		for _, next := range makeNextMoves(curr) {
+			if isOffBoard(next, board) {
+				continue
+			}
+			extra, ok := cost(next, turns[curr]+1)
+			if !ok {
+				continue
+			}
+			nextCost := costs[curr] + 1 + extra
+			if known, seen := costs[next]; seen && known <= nextCost {
+				continue
+			}
+			costs[next] = nextCost
+			turns[next] = turns[curr] + 1
+			from[next] = curr
+			heap.Push(open, pathNode{coord: next, priority: nextCost + distanceTo(next, target)})
+		}
+	}
+	return Path{}, false
+}
+
+// FindSurvivablePath is FindPath that rejects a route if walking it would use
+// up our health before we get to the target. If the cheapest route is lethal
+// it falls back to the route costing the least health, still steering clear
+// of whatever else cost avoids.
+func FindSurvivablePath(source, target Coord, board Board, health, hazardDamage int, cost CellCost) (Path, bool) {
+	path, ok := FindPath(source, target, board, cost)
+	if !ok {
+		return Path{}, false
+	}
+	var alive bool
+	if path.HealthCost, alive = healthCost(path.Coords, board, health, hazardDamage); alive {
+		return path, true
+	}
+
+	// hazard damage outweighs a head zone, so health comes first
+	stacks := hazardStacks(board)
+	path, ok = FindPath(source, target, board, func(c Coord, turn int) (int, bool) {
+		extra, ok := cost(c, turn)
+		if !ok {
+			return 0, false
+		}
+		return extra + stacks[c]*hazardDamage*headZoneStepCost, true
+	})
+	if !ok {
+		return Path{}, false
+	}
+	path.HealthCost, alive = healthCost(path.Coords, board, health, hazardDamage)
+	return path, alive
+}
+
+func walkBack(source, target Coord, from map[Coord]Coord) []Coord {
+	coords := make([]Coord, 0)
+	for c := target; c != source; c = from[c] {
+		coords = append(coords, c)
+	}
+	for i, j := 0, len(coords)-1; i < j; i, j = i+1, j-1 {
+		coords[i], coords[j] = coords[j], coords[i]
+	}
+	return coords
+}
+
+type pathNode struct {
+	coord    Coord
+	priority int
+}
+
+type pathQueue []pathNode
+
+func (q pathQueue) Len() int            { return len(q) }
+func (q pathQueue) Less(i, j int) bool  { return q[i].priority < q[j].priority }
+func (q pathQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x interface{}) { *q = append(*q, x.(pathNode)) }
+func (q *pathQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	node := old[n-1]
+	*q = old[:n-1]
+	return node
+}
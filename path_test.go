@@ -0,0 +1,81 @@
+package main
+
+import "testing"
+
+func noExtraCost(c Coord, turn int) (int, bool) { return 0, true }
+
+func TestFindPath(t *testing.T) {
+	board, err := ParseASCIIBoard(`
+		.....
+		.bbB.
+		A....
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	you, _ := findSnake("A", board.Snakes)
+
+	path, ok := FindPath(you.Head, Coord{2, 2}, board, noExtraCost)
+	if !ok || path.Cost != 4 || path.Len() != 4 || path.Coords[3] != (Coord{2, 2}) {
+		t.Errorf("path %v costs %d, want 4 steps", path.Coords, path.Cost)
+	}
+
+	// an expensive cell is worth going around when the detour is cheaper
+	pricey := func(c Coord, turn int) (int, bool) {
+		if c == (Coord{1, 0}) {
+			return 5, true
+		}
+		return 0, true
+	}
+	path, ok = FindPath(you.Head, Coord{2, 0}, board, pricey)
+	if !ok || path.Cost != 4 || hasCoord(Coord{1, 0}, path.Coords) {
+		t.Errorf("path %v costs %d, want 4 going around (1,0)", path.Coords, path.Cost)
+	}
+
+	// B's body is still in the way next turn, but its tail end has gone by turn 2
+	timed := makeTimedMap(board)
+	bodies := func(c Coord, turn int) (int, bool) { return 0, timed.freeAt(c, turn) }
+	path, ok = FindPath(Coord{2, 0}, Coord{2, 2}, board, bodies)
+	if !ok || path.Len() != 4 || path.Coords[1] != (Coord{1, 1}) {
+		t.Errorf("path %v, want 4 steps through B's tail as it moves on", path.Coords)
+	}
+	if _, ok := FindPath(you.Head, Coord{5, 0}, board, noExtraCost); ok {
+		t.Errorf("found a path off the board")
+	}
+}
+
+func TestFindSurvivablePath(t *testing.T) {
+	board, err := ParseASCIIBoard(`
+		.....
+		A###*
+		.....
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	you, _ := findSnake("A", board.Snakes)
+	food := Coord{4, 1}
+	const damage = 14
+
+	// healthy, straight through the hazards
+	path, ok := FindSurvivablePath(you.Head, food, board, 100, damage, noExtraCost)
+	if !ok || path.Len() != 4 || path.HealthCost != 100-maxHealth {
+		t.Errorf("path %v health cost %d, want 4 steps through the hazards", path.Coords, path.HealthCost)
+	}
+
+	// too weak for the hazards, around them
+	path, ok = FindSurvivablePath(you.Head, food, board, 30, damage, noExtraCost)
+	if !ok || path.Len() != 6 {
+		t.Errorf("path %v, want 6 steps around the hazards", path.Coords)
+	}
+	for _, c := range path.Coords {
+		if hasCoord(c, board.Hazards) {
+			t.Errorf("path %v walks into a hazard at %v", path.Coords, c)
+		}
+	}
+
+	// starving, there's no way
+	if path, ok := FindSurvivablePath(you.Head, food, board, 3, damage, noExtraCost); ok {
+		t.Errorf("survived %v on 3 health", path.Coords)
+	}
+}
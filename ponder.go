@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PONDER=1 keeps searching likely next positions between turns
+var ponderEnabled = os.Getenv("PONDER") == "1" || os.Getenv("PONDER") == "true"
+
+// most positions searched while waiting for the next turn
+const ponderPositionLimit = 8
+
+// stop pondering if the next move (or the game end) never arrives
+const ponderTimeLimit = 10 * time.Second
+
+// drop a game's pondering this long after it started, for games whose /end never came
+const ponderTTL = time.Minute
+
+// ponderWorkers is pondering's own pool, smaller than searchWorkers so a
+// ponder never waits on a move being decided, nor holds every core it needs
+var ponderWorkers = make(chan struct{}, workerLimit("PONDER_WORKERS", (runtime.NumCPU()+1)/2))
+
+type ponderer struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+	results map[string]map[string]WeightedMovementSet
+	started map[string]time.Time
+	// games whose last move went through searchMoves, only they can use a ponder
+	searched map[string]time.Time
+}
+
+var pondering = ponderer{
+	cancels:  make(map[string]context.CancelFunc),
+	results:  make(map[string]map[string]WeightedMovementSet),
+	started:  make(map[string]time.Time),
+	searched: make(map[string]time.Time),
+}
+
+// positionKey identifies everything fillToDepth looks at
+func positionKey(start Coord, depthLimit int, board Board) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%dx%d|%v|%d|", board.Width, board.Height, start, depthLimit)
+
+	snakes := make([]string, 0, len(board.Snakes))
+	for _, s := range board.Snakes {
+		snakes = append(snakes, fmt.Sprintf("%s:%v", s.ID, s.Body))
+	}
+	sort.Strings(snakes)
+	fmt.Fprintf(&b, "%s|%s|%s", strings.Join(snakes, ";"), sortedCoords(board.Food), sortedCoords(board.Hazards))
+	return b.String()
+}
+
+func sortedCoords(coords []Coord) string {
+	sorted := make([]Coord, len(coords))
+	copy(sorted, coords)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].X == sorted[j].X {
+			return sorted[i].Y < sorted[j].Y
+		}
+		return sorted[i].X < sorted[j].X
+	})
+	return fmt.Sprint(sorted)
+}
+
+// searchMoves returns the pondered search for this position if we have one,
+// otherwise searches it now
+func searchMoves(ctx context.Context, state GameState) WeightedMovementSet {
+	key := positionKey(state.You.Head, state.You.Length, state.Board)
+	moves, ok := pondering.take(state, key)
+	if ok {
+		log.Printf("[%s] Reusing pondered search for turn %d", state.You.Name, state.Turn)
+	} else {
+		moves = fillToDepthWithin(ctx, state.You.Head, state.You.Length, state.Board)
+	}
+	markLethalHazards(state, moves)
+	return moves
+}
+
+// take stops any pondering for the game and returns the result for key
+func (p *ponderer) take(state GameState, key string) (WeightedMovementSet, bool) {
+	gameKey := gameKey(state)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	results := p.results[gameKey]
+	p.forget(gameKey)
+	if ponderEnabled {
+		p.searched[gameKey] = time.Now()
+	}
+
+	moves, ok := results[key]
+	if !ok {
+		return nil, false
+	}
+	// callers filter the set in place, hand out a copy
+	copied := make(WeightedMovementSet, len(moves))
+	copy(copied, moves)
+	return copied, true
+}
+
+func (p *ponderer) store(gameKey, key string, moves WeightedMovementSet) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.cancels[gameKey]; !ok {
+		// pondering was stopped, the result is stale
+		return
+	}
+	if p.results[gameKey] == nil {
+		p.results[gameKey] = make(map[string]WeightedMovementSet)
+	}
+	p.results[gameKey][key] = moves
+}
+
+// startPondering searches the likely positions after our move in the background,
+// if the mover that made it searches with searchMoves
+func startPondering(state GameState, response BattlesnakeMoveResponse) {
+	if !ponderEnabled {
+		return
+	}
+	movement, ok := parseMovement(response.Move)
+	if !ok {
+		return
+	}
+
+	gameKey := gameKey(state)
+	ctx, cancel := context.WithTimeout(context.Background(), ponderTimeLimit)
+
+	pondering.mu.Lock()
+	pondering.expire(time.Now())
+	if _, ok := pondering.searched[gameKey]; !ok {
+		pondering.mu.Unlock()
+		cancel()
+		return
+	}
+	pondering.forget(gameKey)
+	pondering.cancels[gameKey] = cancel
+	pondering.started[gameKey] = time.Now()
+	pondering.mu.Unlock()
+
+	go func() {
+		positions := likelyNextStates(state, movement, ponderPositionLimit)
+		for _, next := range positions {
+			key := positionKey(next.You.Head, next.You.Length, next.Board)
+			moves := fillToDepthWith(ctx, ponderWorkers, next.You.Head, next.You.Length, next.Board)
+			if ctx.Err() != nil {
+				return
+			}
+			pondering.store(gameKey, key, moves)
+		}
+		log.Printf("[%s] Pondered %d positions after turn %d", state.You.Name, len(positions), state.Turn)
+	}()
+}
+
+// stopPondering drops everything held for the game
+func stopPondering(state GameState) {
+	pondering.mu.Lock()
+	defer pondering.mu.Unlock()
+	pondering.forget(gameKey(state))
+}
+
+// forget cancels and drops a game's pondering, p.mu must be held
+func (p *ponderer) forget(gameKey string) {
+	if cancel, ok := p.cancels[gameKey]; ok {
+		cancel()
+	}
+	delete(p.cancels, gameKey)
+	delete(p.results, gameKey)
+	delete(p.started, gameKey)
+	delete(p.searched, gameKey)
+}
+
+// expire forgets games that started pondering or searched more than ponderTTL ago, p.mu must be held
+func (p *ponderer) expire(now time.Time) {
+	for gameKey, started := range p.started {
+		if now.Sub(started) > ponderTTL {
+			p.forget(gameKey)
+		}
+	}
+	for gameKey, searched := range p.searched {
+		if now.Sub(searched) > ponderTTL {
+			p.forget(gameKey)
+		}
+	}
+}
+
+// likelyNextStates plays our move against the most likely combinations of
+// opponent moves by the standard rules, assuming no new food spawns
+func likelyNextStates(state GameState, movement Movement, limit int) []GameState {
+	snakeMoves := make([][]Coord, len(state.Board.Snakes))
+	for i, s := range state.Board.Snakes {
+		if s.ID == state.You.ID {
+			snakeMoves[i] = []Coord{moveCoord(s.Head, movement)}
+			continue
+		}
+		snakeMoves[i] = likelyMovesByOdds(state, s)
+		if len(snakeMoves[i]) == 0 {
+			// boxed in, it dies wherever it goes
+			snakeMoves[i] = []Coord{moveCoord(s.Head, Up)}
+		}
+	}
+
+	states := make([]GameState, 0, limit)
+	heads := make([]Coord, len(snakeMoves))
+	var combine func(i int)
+	combine = func(i int) {
+		if len(states) >= limit {
+			return
+		}
+		if i == len(snakeMoves) {
+			// nothing to search if we don't survive it
+			if next, _ := stepState(state, heads); hasSnake(state.You.ID, next.Board.Snakes) {
+				states = append(states, next)
+			}
+			return
+		}
+		for _, next := range snakeMoves[i] {
+			heads[i] = next
+			combine(i + 1)
+		}
+	}
+	combine(0)
+	return states
+}
+
+// likelyMoves orders a snake's legal next heads, carrying on straight first
+func likelyMoves(snake Battlesnake, board Board) []Coord {
+	other := describeSnake(snake, board, makeTimedMap(board))
+	moves := make([]Coord, 0, len(other.NextMoves))
+	straight := moveCoord(other.Head, other.PrevMove)
+	if other.HasMoved() && hasCoord(straight, other.NextMoves) {
+		moves = append(moves, straight)
+	}
+	for _, next := range other.NextMoves {
+		if !hasCoord(next, moves) {
+			moves = append(moves, next)
+		}
+	}
+	return moves
+}
+
+func moveCoord(c Coord, m Movement) Coord {
+	switch m {
+	case Up:
+		return Coord{c.X, c.Y + 1}
+	case Right:
+		return Coord{c.X + 1, c.Y}
+	case Down:
+		return Coord{c.X, c.Y - 1}
+	}
+	return Coord{c.X - 1, c.Y}
+}
+
+func parseMovement(move string) (Movement, bool) {
+	switch move {
+	case "up":
+		return Up, true
+	case "right":
+		return Right, true
+	case "down":
+		return Down, true
+	case "left":
+		return Left, true
+	}
+	return Up, false
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStepStateFollowsTheRules(t *testing.T) {
+	board, err := ParseASCIIBoard(`
+		.......
+		Aaa.*..
+		....Bbb
+		.......
+		.......
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	board.Hazards = append(board.Hazards, Coord{0, 2}, Coord{0, 2})
+	you, _ := findSnake("A", board.Snakes)
+	state := GameState{Game: Game{ID: "step"}, Turn: 3, Board: board, You: you}
+	state.Game.Ruleset.Settings.HazardDamagePerTurn = 14
+
+	// A steps onto a doubled hazard, B eats
+	next, eliminated := stepState(state, []Coord{{0, 2}, {4, 3}})
+	if next.Turn != 4 || len(eliminated) != 0 {
+		t.Fatalf("turn %d, eliminated %v", next.Turn, eliminated)
+	}
+	a, _ := findSnake("A", next.Board.Snakes)
+	if a.Health != 100-1-2*14 || a.Length != 3 || a.Body[2] != (Coord{1, 3}) {
+		t.Errorf("A = health %d, body %v", a.Health, a.Body)
+	}
+	if next.You.Health != a.Health {
+		t.Errorf("You wasn't moved with A")
+	}
+	b, _ := findSnake("B", next.Board.Snakes)
+	if b.Health != maxHealth || b.Length != 4 || b.Body[2] != b.Body[3] || len(next.Board.Food) != 0 {
+		t.Errorf("B = health %d, body %v, food left %v", b.Health, b.Body, next.Board.Food)
+	}
+
+	// A runs off the board and B into its own body
+	_, eliminated = stepState(state, []Coord{{-1, 3}, {5, 2}})
+	if len(eliminated) != 2 {
+		t.Errorf("eliminated %v, want both", eliminated)
+	}
+}
+
+func TestPonderingExpires(t *testing.T) {
+	p := ponderer{
+		cancels:  make(map[string]context.CancelFunc),
+		results:  make(map[string]map[string]WeightedMovementSet),
+		started:  make(map[string]time.Time),
+		searched: make(map[string]time.Time),
+	}
+	now := time.Now()
+	cancelled := false
+	p.cancels["stale"] = func() { cancelled = true }
+	p.results["stale"] = map[string]WeightedMovementSet{"position": {}}
+	p.started["stale"] = now.Add(-ponderTTL - time.Second)
+	p.cancels["fresh"] = func() {}
+	p.started["fresh"] = now
+
+	p.expire(now)
+	if _, ok := p.results["stale"]; ok || !cancelled || len(p.started) != 1 {
+		t.Errorf("stale game wasn't forgotten, cancelled %v, started %v", cancelled, p.started)
+	}
+	if _, ok := p.cancels["fresh"]; !ok {
+		t.Errorf("fresh game was forgotten")
+	}
+}
+
+func TestPonderingOnlyAfterSearchMoves(t *testing.T) {
+	defer func(enabled bool) { ponderEnabled = enabled }(ponderEnabled)
+	ponderEnabled = true
+	state := GameState{Game: Game{ID: t.Name()}, Board: Board{Width: 5, Height: 5}}
+	state.You = Battlesnake{ID: "you", Head: Coord{2, 2}, Body: []Coord{{2, 2}, {2, 1}}, Length: 2, Health: 90}
+	state.Board.Snakes = []Battlesnake{state.You}
+	defer stopPondering(state)
+
+	pondering.mu.Lock()
+	pondering.forget(gameKey(state))
+	pondering.mu.Unlock()
+	startPondering(state, BattlesnakeMoveResponse{Move: "up"})
+	pondering.mu.Lock()
+	_, started := pondering.cancels[gameKey(state)]
+	pondering.mu.Unlock()
+	if started {
+		t.Errorf("pondered after a move that never searched")
+	}
+
+	searchMoves(context.Background(), state)
+	startPondering(state, BattlesnakeMoveResponse{Move: "up"})
+	pondering.mu.Lock()
+	_, started = pondering.cancels[gameKey(state)]
+	pondering.mu.Unlock()
+	if !started {
+		t.Errorf("didn't ponder after searchMoves")
+	}
+}
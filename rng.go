@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+)
+
+// gameRand is the randomness for one of our moves. It's seeded from the game,
+// turn and snake, or state.Seed when a simulation sets one, so replaying a
+// logged position makes the same choice and games never share a source.
+func gameRand(state GameState) *rand.Rand {
+	h := fnv.New64a()
+	if state.Seed != 0 {
+		fmt.Fprintf(h, "seed:%d", state.Seed)
+	} else {
+		fmt.Fprintf(h, "game:%s", state.Game.ID)
+	}
+	fmt.Fprintf(h, "|%d|%s", state.Turn, state.You.ID)
+	return rand.New(rand.NewSource(int64(h.Sum64())))
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestGameRandReplays(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	state := syntheticState(11, 11, 4, 7)
+	state.Game.ID = "replay"
+
+	first := gameRand(state).Int63()
+	if again := gameRand(state).Int63(); again != first {
+		t.Errorf("same position drew %d then %d", first, again)
+	}
+
+	other := state
+	other.Game.ID = "another game"
+	if gameRand(other).Int63() == first {
+		t.Errorf("different games drew the same number")
+	}
+
+	seeded := state
+	seeded.Seed = 42
+	if gameRand(seeded).Int63() == first {
+		t.Errorf("an explicit seed should replace the game ID")
+	}
+
+	move := moveLessBlindWandering(state).Move
+	for i := 0; i < 10; i++ {
+		if again := moveLessBlindWandering(state).Move; again != move {
+			t.Fatalf("replaying the position moved %s, first time %s", again, move)
+		}
+	}
+}
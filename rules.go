@@ -0,0 +1,105 @@
+package main
+
+import "log"
+
+// hungry enough for the rules to prefer food
+const rulesHungryHealth = 50
+
+type moveRule struct {
+	name   string
+	prefer func(state GameState, m PossibleMove) bool
+}
+
+// rules in order of importance, each narrows the candidates unless it would rule them all out
+var moveRules = []moveRule{
+	{"avoid heads that can beat us", func(state GameState, m PossibleMove) bool {
+		return !m.IsNearBiggerSnake
+	}},
+	{"eat when hungry", func(state GameState, m PossibleMove) bool {
+		return state.You.Health >= rulesHungryHealth || m.HasFood
+	}},
+	{"hunt smaller snakes", func(state GameState, m PossibleMove) bool {
+		return m.IsNearSmallerSnake
+	}},
+	{"stay out of corners", func(state GameState, m PossibleMove) bool {
+		return !m.IsCorner
+	}},
+	{"stay off the border", func(state GameState, m PossibleMove) bool {
+		return !m.IsOnBorder
+	}},
+}
+
+// moveByRules filters the PossibleMove table through moveRules
+func moveByRules(state GameState) BattlesnakeMoveResponse {
+	others := opponents(state)
+	trace := newTrace()
+	zones := headZonesFor(state, 1)
+	all := FindNextMoves(state.You, others, state.Board.Food, state.Board, state.Game.Ruleset.Settings.HazardDamagePerTurn, zones)
+	trace.Phase("next moves")
+
+	candidates := make([]PossibleMove, 0, len(all))
+	for _, m := range all {
+		if m.IsFatal() {
+			trace.Drop(m.Dir.asString(), "fatal")
+			continue
+		}
+		passed := 0.0
+		scores := make(map[string]float64, len(moveRules))
+		for _, rule := range moveRules {
+			scores[rule.name] = 0
+			if rule.prefer(state, m) {
+				scores[rule.name] = 1
+				passed++
+			}
+		}
+		trace.Score(m.Dir.asString(), passed, scores)
+		candidates = append(candidates, m)
+	}
+	if len(candidates) == 0 {
+		log.Printf("[%s] No moves left by the rules", state.You.Name)
+		return trace.Respond(BattlesnakeMoveResponse{Move: lastResort(all).Dir.asString()}, "no moves left by the rules")
+	}
+
+	for _, rule := range moveRules {
+		preferred := make([]PossibleMove, 0, len(candidates))
+		dropped := make([]PossibleMove, 0, len(candidates))
+		for _, m := range candidates {
+			if rule.prefer(state, m) {
+				preferred = append(preferred, m)
+			} else {
+				dropped = append(dropped, m)
+			}
+		}
+		if len(preferred) > 0 {
+			for _, m := range dropped {
+				trace.Drop(m.Dir.asString(), rule.name)
+			}
+			candidates = preferred
+		} else {
+			log.Printf("[%s] Rule %q ruled out every move, ignoring it", state.You.Name, rule.name)
+		}
+	}
+
+	return trace.Respond(BattlesnakeMoveResponse{Move: candidates[0].Dir.asString()}, "rules, first move left")
+}
+
+// lastResort picks among fatal moves, staying on the board and, if it can,
+// out of bodies
+func lastResort(all []PossibleMove) PossibleMove {
+	best := all[0]
+	rank := func(m PossibleMove) int {
+		switch {
+		case m.IsOffBoard:
+			return 0
+		case m.IsOccupied:
+			return 1
+		}
+		return 2
+	}
+	for _, m := range all[1:] {
+		if rank(m) > rank(best) {
+			best = m
+		}
+	}
+	return best
+}
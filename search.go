@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// default move timeout when the game doesn't tell us one
+const defaultMoveTimeout = 500 * time.Millisecond
+
+// time held back from the move timeout for network and encoding
+const searchLatencyMargin = 150 * time.Millisecond
+
+// searchWorkers is shared by every game running on this machine so one
+// busy game can't starve the others
+var searchWorkers = make(chan struct{}, workerLimit("SEARCH_WORKERS", runtime.NumCPU()))
+
+// workerLimit reads a pool size from the environment, defaulting to fallback
+func workerLimit(env string, fallback int) int {
+	val := os.Getenv(env)
+	if len(val) == 0 {
+		return fallback
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil || n < 1 {
+		log.Printf("ERROR: Invalid %s %q, using %d", env, val, fallback)
+		return fallback
+	}
+	return n
+}
+
+// searchContext returns a context that expires before the game's move timeout
+func searchContext(state GameState) (context.Context, context.CancelFunc) {
+	timeout := time.Duration(state.Game.Timeout) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultMoveTimeout
+	}
+	budget := timeout - searchLatencyMargin
+	if budget < timeout/2 {
+		budget = timeout / 2
+	}
+	return context.WithTimeout(context.Background(), budget)
+}
+
+// searchRoots evaluates n independent roots (root moves, search trees) in
+// parallel, bounded by searchWorkers. Roots that never get a worker before the
+// context is done are skipped, so eval must leave a usable default behind.
+// eval must not call searchRoots itself or it can deadlock waiting for a worker.
+func searchRoots(ctx context.Context, n int, eval func(ctx context.Context, i int)) {
+	searchRootsWith(ctx, searchWorkers, n, eval)
+}
+
+// searchRootsWith is searchRoots bounded by the given pool of workers
+func searchRootsWith(ctx context.Context, workers chan struct{}, n int, eval func(ctx context.Context, i int)) {
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			select {
+			case workers <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-workers }()
+			eval(ctx, i)
+		}(i)
+	}
+	wg.Wait()
+}
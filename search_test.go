@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestSearchPastDeadlineLeavesUsableMoves(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	board, err := ParseASCIIBoard(`
+		.....
+		.....
+		Aa...
+		.a...
+		.....
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	you, _ := findSnake("A", board.Snakes)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	moves := fillToDepthWithin(ctx, you.Head, you.Length, board)
+	if len(moves) != 4 {
+		t.Fatalf("got %d moves, want all 4", len(moves))
+	}
+	for _, m := range moves {
+		if !m.partial {
+			t.Errorf("%s wasn't marked partial after the deadline", m.movement.asString())
+		}
+		if dies := m.movement == Left || m.movement == Right; m.certainDeath != dies {
+			t.Errorf("%s certain death = %v, want %v", m.movement.asString(), m.certainDeath, dies)
+		}
+	}
+
+	safe := moves.avoidCertainDeath()
+	if best := safe.bestMoveForRoaming(you); best.movement != Up && best.movement != Down {
+		t.Errorf("roamed %s without a search", best.movement.asString())
+	}
+	if !safe[0].hasRoomFor(you.Length) {
+		t.Errorf("an unfinished search counted as a dead end")
+	}
+
+	for _, m := range fillToDepthWithin(context.Background(), you.Head, you.Length, board) {
+		if m.partial {
+			t.Errorf("%s marked partial after a full search", m.movement.asString())
+		}
+	}
+}
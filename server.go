@@ -12,6 +12,24 @@ type SnakeStartFunc func(state GameState)
 type SnakeInfoFunc func() BattlesnakeInfoResponse
 type SnakeEndFunc func(state GameState)
 
+// NamedMover is a mover and the name it's served under
+type NamedMover struct {
+	Name  string
+	Mover SnakeMoverFunc
+}
+
+// registeredMovers lists every mover the server plays with
+func registeredMovers() []NamedMover {
+	return []NamedMover{
+		{"coward", moveLessBlindWandering},
+		{"smart", moveSmart},
+		{"aggressive", moveAggressive},
+		{"weighted", StrategyMover(defaultStrategy)},
+		{"chaser", moveTailChaser},
+		{"rules", moveByRules},
+	}
+}
+
 func HandleStart(w http.ResponseWriter, r *http.Request) {
 	state := GameState{}
 	err := json.NewDecoder(r.Body).Decode(&state)
@@ -67,6 +85,9 @@ const ServerIdSal = "battlesnake/dave-smith/salazar"
 const ServerIdVNext = "battlesnake/dave-smith/vNext"
 const ServerIdCoward = "battlesnake/dave-smith/coward"
 const ServerIdAgg = "battlesnake/dave-smith/aggressive"
+const ServerIdWeighted = "battlesnake/dave-smith/weighted"
+const ServerIdChaser = "battlesnake/dave-smith/chaser"
+const ServerIdRules = "battlesnake/dave-smith/rules"
 
 func withServerID(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -102,9 +123,12 @@ func SnakeHandlerMove(mover SnakeMoverFunc, serverId string, next http.HandlerFu
 		}
 		log.Printf("[%s] Head position: (%d,%d), Body: %v, Health: %d, Length: %d", state.You.Name, state.You.Head.X, state.You.Head.Y, state.You.Body, state.You.Health, state.You.Length)
 
+		observeOpponents(state)
 		response := mover(state)
 
 		log.Printf("[%s] Moving %s", state.You.Name, response.Move)
+		logState(state, response.Move)
+		writeTrace(w, state, response)
 
 		w.Header().Set("Content-Type", "application/json")
 		err = json.NewEncoder(w).Encode(response)
@@ -112,6 +136,8 @@ func SnakeHandlerMove(mover SnakeMoverFunc, serverId string, next http.HandlerFu
 			log.Printf("ERROR: Failed to encode move response, %s", err)
 			return
 		}
+
+		startPondering(state, response)
 	}
 }
 
@@ -128,6 +154,7 @@ func SnakeHandlerStart(starter SnakeStartFunc, serverId string, next http.Handle
 			return
 		}
 		log.Printf("[%s] Head position: (%d,%d), Body: %v, Health: %d, Length: %d", state.You.Name, state.You.Head.X, state.You.Head.Y, state.You.Body, state.You.Health, state.You.Length)
+		logState(state, "")
 
 		starter(state)
 
@@ -160,6 +187,7 @@ func SnakeHandlerEnd(gameEnd SnakeEndFunc, serverId string, next http.HandlerFun
 			log.Printf("ERROR: Failed to decode move json, %s", err)
 			return
 		}
+		logState(state, "")
 		gameEnd(state)
 	}
 }
@@ -186,10 +214,10 @@ func RunServer() {
 	http.HandleFunc("/move", withServerID(HandleMove))
 	http.HandleFunc("/end", withServerID(HandleEnd))
 
-	http.HandleFunc("/agg", withServerID(HandleIndex))
-	http.HandleFunc("/agg/start", withServerID(HandleStart))
-	http.HandleFunc("/agg/move", withServerID(HandleMove))
-	http.HandleFunc("/agg/end", withServerID(HandleEnd))
+	http.HandleFunc("/agg", SnakeHandlerInfo(infoAggressive, ServerIdAgg, nil))
+	http.HandleFunc("/agg/start", SnakeHandlerStart(start, ServerIdAgg, nil))
+	http.HandleFunc("/agg/move", SnakeHandlerMove(moveAggressive, ServerIdAgg, nil))
+	http.HandleFunc("/agg/end", SnakeHandlerEnd(end, ServerIdAgg, nil))
 
 	// http.HandleFunc("/coward", withServerID(HandleIndex))
 	http.HandleFunc("/coward", SnakeHandlerInfo(infoCoward, ServerIdCoward, nil))
@@ -199,14 +227,36 @@ func RunServer() {
 
 	http.HandleFunc("/vnext", SnakeHandlerInfo(infoVNext, ServerIdVNext, nil))
 	http.HandleFunc("/vnext/start", SnakeHandlerStart(start, ServerIdVNext, nil))
-	http.HandleFunc("/vnext/move", SnakeHandlerMove(moveSmart, ServerIdVNext, nil))
+	params := loadParamsFromEnv()
+	smart := SmartMover(params)
+	http.HandleFunc("/vnext/move", SnakeHandlerMove(smart, ServerIdVNext, nil))
 	http.HandleFunc("/vnext/end", SnakeHandlerEnd(end, ServerIdVNext, nil))
 
 	http.HandleFunc("/salazar", SnakeHandlerInfo(infoSalazar, ServerIdSal, nil))
 	http.HandleFunc("/salazar/start", SnakeHandlerStart(start, ServerIdSal, nil))
-	http.HandleFunc("/salazar/move", SnakeHandlerMove(moveSmart, ServerIdSal, nil))
+	http.HandleFunc("/salazar/move", SnakeHandlerMove(smart, ServerIdSal, nil))
 	http.HandleFunc("/salazar/end", SnakeHandlerEnd(end, ServerIdSal, nil))
 
+	weighted := StrategyMover(loadStrategyFromEnv())
+	http.HandleFunc("/weighted", SnakeHandlerInfo(infoWeighted, ServerIdWeighted, nil))
+	http.HandleFunc("/weighted/start", SnakeHandlerStart(start, ServerIdWeighted, nil))
+	http.HandleFunc("/weighted/move", SnakeHandlerMove(weighted, ServerIdWeighted, nil))
+	http.HandleFunc("/weighted/end", SnakeHandlerEnd(end, ServerIdWeighted, nil))
+
+	http.HandleFunc("/chaser", SnakeHandlerInfo(infoChaser, ServerIdChaser, nil))
+	http.HandleFunc("/chaser/start", SnakeHandlerStart(start, ServerIdChaser, nil))
+	http.HandleFunc("/chaser/move", SnakeHandlerMove(TailChaserMover(params), ServerIdChaser, nil))
+	http.HandleFunc("/chaser/end", SnakeHandlerEnd(end, ServerIdChaser, nil))
+
+	http.HandleFunc("/rules", SnakeHandlerInfo(infoRules, ServerIdRules, nil))
+	http.HandleFunc("/rules/start", SnakeHandlerStart(start, ServerIdRules, nil))
+	http.HandleFunc("/rules/move", SnakeHandlerMove(moveByRules, ServerIdRules, nil))
+	http.HandleFunc("/rules/end", SnakeHandlerEnd(end, ServerIdRules, nil))
+
+	if debugEndpointEnabled {
+		http.HandleFunc("/debug/evaluate", withServerID(HandleDebugEvaluate))
+	}
+
 	log.Printf("Running Battlesnake at http://0.0.0.0:%s...\n", port)
 	log.Fatal(http.ListenAndServe(":"+port, nil))
 }
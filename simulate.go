@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// SimSnake is a snake in a self-play game and the mover playing it
+type SimSnake struct {
+	Name  string
+	Mover SnakeMoverFunc
+}
+
+// SimConfig is the board and rules a self-play game is played under
+type SimConfig struct {
+	Width           int
+	Height          int
+	MaxTurns        int
+	FoodSpawnChance int
+	MinimumFood     int
+}
+
+var defaultSimConfig = SimConfig{Width: 11, Height: 11, MaxTurns: 300, FoodSpawnChance: 15, MinimumFood: 1}
+
+// SimResult is how a self-play game ended
+type SimResult struct {
+	Turns int
+	// empty when nobody survived, or more than one snake lasted to the turn limit
+	Winner string
+	// the turn each snake was eliminated on, survivors aren't listed
+	Eliminated map[string]int
+}
+
+// Survived is how many turns the snake lasted
+func (r SimResult) Survived(name string) int {
+	if turn, ok := r.Eliminated[name]; ok {
+		return turn
+	}
+	return r.Turns
+}
+
+// Simulate plays a standard game between the snakes locally. Everything random,
+// the snakes' moves included, comes from seed so the same game replays exactly.
+func Simulate(snakes []SimSnake, config SimConfig, seed int64) SimResult {
+	rng := rand.New(rand.NewSource(seed))
+	state := GenerateState(BoardSpec{Width: config.Width, Height: config.Height, Snakes: len(snakes), StandardStart: true}, rng)
+	state.Game.ID = fmt.Sprintf("sim-%d", seed)
+	state.Game.Ruleset.Settings.FoodSpawnChance = config.FoodSpawnChance
+	state.Game.Ruleset.Settings.MinimumFood = config.MinimumFood
+	state.Seed = seed
+	for i := range state.Board.Snakes {
+		state.Board.Snakes[i].ID = snakes[i].Name
+		state.Board.Snakes[i].Name = snakes[i].Name
+	}
+	result := SimResult{Eliminated: make(map[string]int)}
+	movers := make(map[string]SnakeMoverFunc, len(snakes))
+	for _, s := range snakes {
+		movers[s.Name] = s.Mover
+	}
+
+	for state.Turn < config.MaxTurns && simPlaying(state, len(snakes)) {
+		heads := make([]Coord, len(state.Board.Snakes))
+		for i, s := range state.Board.Snakes {
+			view := state
+			view.You = s
+			observeOpponents(view)
+			movement, ok := parseMovement(movers[s.Name](view).Move)
+			if !ok {
+				// the server treats a bad move as up
+				movement = Up
+			}
+			heads[i] = moveCoord(s.Head, movement)
+		}
+
+		var eliminated []string
+		state, eliminated = stepState(state, heads)
+		for _, name := range eliminated {
+			result.Eliminated[name] = state.Turn
+		}
+		simSpawnFood(&state.Board, config, rng)
+	}
+
+	result.Turns = state.Turn
+	if len(state.Board.Snakes) == 1 {
+		result.Winner = state.Board.Snakes[0].Name
+	}
+	for _, s := range snakes {
+		view := state
+		view.You = Battlesnake{ID: s.Name, Name: s.Name}
+		end(view)
+	}
+	return result
+}
+
+// simPlaying is true while the game isn't decided, a solo game plays until the snake dies
+func simPlaying(state GameState, started int) bool {
+	if started == 1 {
+		return len(state.Board.Snakes) == 1
+	}
+	return len(state.Board.Snakes) > 1
+}
+
+// stepState plays a turn of the standard rules with each snake moving to its
+// new head: hunger and hazards take their health, food tops it up and grows
+// the snake, then snakes that starved, left the board or collided are
+// eliminated. Returns the next state and the names of the eliminated snakes.
+func stepState(state GameState, heads []Coord) (GameState, []string) {
+	next := state
+	next.Turn = state.Turn + 1
+	moved := make([]Battlesnake, len(state.Board.Snakes))
+	stacks := hazardStacks(state.Board)
+	damage := state.Game.Ruleset.Settings.HazardDamagePerTurn
+
+	for i, s := range state.Board.Snakes {
+		head := heads[i]
+		body := make([]Coord, 0, len(s.Body)+1)
+		body = append(body, head)
+		body = append(body, s.Body[:len(s.Body)-1]...)
+		s.Health -= stepDamage(head, stacks, damage, state.Board.Food)
+		if hasFood(head, state.Board.Food) {
+			s.Health = maxHealth
+			body = append(body, body[len(body)-1])
+		}
+		s.Body = body
+		s.Head = head
+		s.Length = len(body)
+		moved[i] = s
+		if s.ID == state.You.ID {
+			next.You = s
+		}
+	}
+
+	next.Board.Food = make([]Coord, 0, len(state.Board.Food))
+	for _, f := range state.Board.Food {
+		if !hasCoord(f, heads) {
+			next.Board.Food = append(next.Board.Food, f)
+		}
+	}
+
+	next.Board.Snakes = make([]Battlesnake, 0, len(moved))
+	movedBoard := next.Board
+	movedBoard.Snakes = moved
+	eliminated := make([]string, 0)
+	for _, s := range moved {
+		if simEliminated(s, movedBoard) {
+			eliminated = append(eliminated, s.Name)
+			continue
+		}
+		next.Board.Snakes = append(next.Board.Snakes, s)
+	}
+	return next, eliminated
+}
+
+// simEliminated applies the standard rules to a snake that has just moved
+func simEliminated(s Battlesnake, board Board) bool {
+	if s.Health <= 0 || isOffBoard(s.Head, board) {
+		return true
+	}
+	for _, other := range board.Snakes {
+		for i, c := range other.Body {
+			if c != s.Head {
+				continue
+			}
+			if i > 0 {
+				return true
+			}
+			if other.ID != s.ID && other.Length >= s.Length {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// simSpawnFood tops the board up to the minimum and sometimes adds one more
+func simSpawnFood(board *Board, config SimConfig, rng *rand.Rand) {
+	spawn := config.MinimumFood - len(board.Food)
+	if spawn <= 0 && rng.Intn(100) < config.FoodSpawnChance {
+		spawn = 1
+	}
+	free := freeCells(*board)
+	for ; spawn > 0 && len(free) > 0; spawn-- {
+		i := rng.Intn(len(free))
+		board.Food = append(board.Food, free[i])
+		free = append(free[:i], free[i+1:]...)
+	}
+}
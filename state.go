@@ -1,5 +1,7 @@
 package main
 
+import "sort"
+
 type PossibleMove struct {
 	Dir                      Movement
 	Curr                     Coord
@@ -12,9 +14,13 @@ type PossibleMove struct {
 	IsOccupiedBySmallerSnake bool
 	IsCorner                 bool
 	IsOnBorder               bool
-	//IsOnObstacle             bool
+	IsOnObstacle             bool
+	// the hazard damage would use up the last of our health
+	IsLethal           bool
 	IsNearAnySnake     bool
 	IsNearSmallerSnake bool
+	// a head at least as long as ours could move there too
+	IsNearBiggerSnake bool
 	//IsAdjacentToSmallerSnake bool
 	//NearestFood              int
 	//NearestSnake             int
@@ -25,6 +31,8 @@ type PossibleMove struct {
 }
 
 type OtherSnake struct {
+	ID           string
+	Name         string
 	Head         Coord
 	Prev         Coord
 	Tail         Coord
@@ -39,27 +47,181 @@ type OtherSnake struct {
 	IsInCorner   bool
 }
 
+// FoodRace is how a race to a food against the nearest opponent ends
+type FoodRace int
+
+const (
+	RaceUnreachable FoodRace = iota
+	RaceWin
+	RaceTieWin
+	RaceTieLose
+	RaceLose
+)
+
 type GameFood struct {
-	Location     Coord
-	NearestSnake Coord
+	Location         Coord
+	NearestSnake     Coord
+	NearestSnakeName string
+	// path distances, -1 when there's no path
 	DistToSnake  int
 	DistToMe     int
 	IsOnObstacle bool
+	Race         FoodRace
+	Path         Path
 }
 
-func FindFood(you Battlesnake, other []Battlesnake, food []Coord) []GameFood {
-	f := make([]GameFood, 0)
+// FindFood works out who gets to each food first, closest food to us first
+func FindFood(you Battlesnake, other []Battlesnake, board Board, hazardDamage int) []GameFood {
+	f := make([]GameFood, 0, len(board.Food))
+	// every snake paths the way we do, so the race compares like with like
+	ours := foodPathCost(you, board, hazardDamage)
+	theirs := make([]CellCost, len(other))
+	for i, s := range other {
+		theirs[i] = foodPathCost(s, board, hazardDamage)
+	}
+
+	for _, loc := range board.Food {
+		food := GameFood{Location: loc, DistToSnake: -1, DistToMe: -1, IsOnObstacle: hasCoord(loc, board.Hazards)}
+		if path, ok := FindSurvivablePath(you.Head, loc, board, you.Health, hazardDamage, ours); ok {
+			food.DistToMe = path.Len()
+			food.Path = path
+		}
+
+		var rival Battlesnake
+		for i, s := range other {
+			path, ok := FindSurvivablePath(s.Head, loc, board, s.Health, hazardDamage, theirs[i])
+			if !ok {
+				continue
+			}
+			if food.DistToSnake < 0 || path.Len() < food.DistToSnake || (path.Len() == food.DistToSnake && s.Length > rival.Length) {
+				food.DistToSnake = path.Len()
+				food.NearestSnake = s.Head
+				food.NearestSnakeName = s.Name
+				rival = s
+			}
+		}
 
+		switch {
+		case food.DistToMe < 0:
+			food.Race = RaceUnreachable
+		case food.DistToSnake < 0 || food.DistToMe < food.DistToSnake:
+			food.Race = RaceWin
+		case food.DistToMe == food.DistToSnake && you.Length > rival.Length:
+			food.Race = RaceTieWin
+		case food.DistToMe == food.DistToSnake:
+			food.Race = RaceTieLose
+		default:
+			food.Race = RaceLose
+		}
+		f = append(f, food)
+	}
+
+	sort.SliceStable(f, func(i, j int) bool {
+		if (f[i].DistToMe < 0) != (f[j].DistToMe < 0) {
+			return f[j].DistToMe < 0
+		}
+		return f[i].DistToMe < f[j].DistToMe
+	})
 	return f
 }
 
-func FindOtherSnakes(snakes []Battlesnake, board Board) []OtherSnake {
-	s := make([]OtherSnake, 0)
+// Winnable is true when we get to the food first, or meet the rival there and win
+func (f GameFood) Winnable() bool {
+	return f.Race == RaceWin || f.Race == RaceTieWin
+}
 
+// FindOtherSnakes describes where each snake is and where it can go next
+func FindOtherSnakes(snakes []Battlesnake, board Board) []OtherSnake {
+	s := make([]OtherSnake, 0, len(snakes))
+	timed := makeTimedMap(board)
+	for _, snake := range snakes {
+		s = append(s, describeSnake(snake, board, timed))
+	}
 	return s
 }
 
-func FindNextMoves(you Battlesnake, other []Battlesnake, food []Coord, board Game) []PossibleMove {
-	var m [4]PossibleMove
-	return m[:]
+func describeSnake(snake Battlesnake, board Board, timed TimedMap) OtherSnake {
+	other := OtherSnake{
+		ID:           snake.ID,
+		Name:         snake.Name,
+		Head:         snake.Head,
+		Prev:         snake.Head,
+		Tail:         snake.Head,
+		Body:         snake.Body,
+		HeadlessBody: make([]Coord, 0),
+		PrevMove:     Up,
+		Length:       snake.Length,
+		Health:       snake.Health,
+		NextMoves:    make([]Coord, 0, 4),
+		IsOnObstacle: hasCoord(snake.Head, board.Hazards),
+		IsOnBorder:   isOnBorder(snake.Head, board),
+		IsInCorner:   isCorner(snake.Head, board),
+	}
+	if len(snake.Body) > 0 {
+		other.Tail = snake.Body[len(snake.Body)-1]
+		other.HeadlessBody = snake.Body[1:]
+	}
+	if len(snake.Body) > 1 {
+		other.Prev = snake.Body[1]
+	}
+	if other.Prev != other.Head {
+		other.PrevMove = UseMovement(other.Prev, other.Head)
+	}
+
+	for _, next := range makeNextMoves(snake.Head) {
+		if timed.freeAt(next, 1) {
+			other.NextMoves = append(other.NextMoves, next)
+		}
+	}
+	return other
+}
+
+// HasMoved is false at the start of a game while the snake is still stacked up
+func (s OtherSnake) HasMoved() bool {
+	return s.Prev != s.Head
+}
+
+// FindNextMoves describes each of our four candidate moves. Bodies count as
+// occupied only if they'll still be there next turn, heads are near if
+// they can reach the move next turn through the zones given.
+func FindNextMoves(you Battlesnake, other []Battlesnake, food []Coord, board Board, hazardDamage int, zones []HeadZone) []PossibleMove {
+	timed := makeTimedMap(board)
+	zones = headZonesWithin(zones, 1)
+
+	prev := you.Head
+	if len(you.Body) > 1 {
+		prev = you.Body[1]
+	}
+
+	moves := make([]PossibleMove, 0, 4)
+	for _, m := range []Movement{Up, Right, Down, Left} {
+		next := moveCoord(you.Head, m)
+		offBoard := isOffBoard(next, board)
+		moves = append(moves, PossibleMove{
+			Dir:                      m,
+			Curr:                     you.Head,
+			Next:                     next,
+			Prev:                     prev,
+			IsOffBoard:               offBoard,
+			IsBackwards:              next == prev && prev != you.Head,
+			HasFood:                  hasFood(next, food),
+			IsOccupied:               !offBoard && !timed.freeAt(next, 1),
+			IsOccupiedBySmallerSnake: isOccupiedBySmallerSnake(next, you, other),
+			IsCorner:                 isCorner(next, board),
+			IsOnBorder:               !offBoard && isOnBorder(next, board),
+			IsOnObstacle:             hasCoord(next, board.Hazards),
+			IsLethal:                 lethalStep(next, board, you.Health, hazardDamage),
+			IsNearAnySnake:           isNearSnakeHead(next, zones),
+			IsNearSmallerSnake:       isNearSmallerSnakeHead(next, you, zones),
+			IsNearBiggerSnake:        isNearBiggerSnakeHead(next, you, zones),
+			NearestSmallerSnake:      nearestSmallerSnake(next, you, other),
+		})
+	}
+	return moves
+}
+
+// IsFatal is true for moves that kill us outright. Backwards is only fatal
+// while the neck is still there, a length 2 snake can follow its tail.
+func (m PossibleMove) IsFatal() bool {
+	return m.IsOffBoard || m.IsOccupied || m.IsLethal
 }
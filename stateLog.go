@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+)
+
+// STATE_LOG=path appends every GameState the server is sent, and our move, to a JSONL file,
+// ready for `go run . golden` to pick decisions out of. The end of each game is
+// logged too, so `go run . disagree` can tell whether we died.
+var stateLogPath = os.Getenv("STATE_LOG")
+
+// LoggedState is one line of the state log, with the move we answered if it was a /move
+type LoggedState struct {
+	GameState
+	Move string `json:"move,omitempty"`
+}
+
+var stateLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// logState records the state, and our move from it if there was one, if state logging is on
+func logState(state GameState, move string) {
+	if len(stateLogPath) == 0 {
+		return
+	}
+	line, err := json.Marshal(LoggedState{GameState: state, Move: move})
+	if err != nil {
+		log.Printf("ERROR: Failed to encode state for the log, %s", err)
+		return
+	}
+
+	stateLog.mu.Lock()
+	defer stateLog.mu.Unlock()
+	if stateLog.file == nil {
+		f, err := os.OpenFile(stateLogPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Printf("ERROR: Failed to open state log, %s", err)
+			return
+		}
+		stateLog.file = f
+	}
+	if _, err := stateLog.file.Write(append(line, '\n')); err != nil {
+		log.Printf("ERROR: Failed to write state log, %s", err)
+	}
+}
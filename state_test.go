@@ -0,0 +1,290 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestFindOtherSnakes(t *testing.T) {
+	board := Board{Width: 7, Height: 7, Hazards: []Coord{{0, 0}}}
+	mover := Battlesnake{ID: "a", Name: "a", Health: 80, Length: 3, Head: Coord{3, 3}, Body: []Coord{{3, 3}, {3, 2}, {3, 1}}}
+	cornered := Battlesnake{ID: "b", Name: "b", Health: 50, Length: 3, Head: Coord{0, 0}, Body: []Coord{{0, 0}, {1, 0}, {2, 0}}}
+	stacked := Battlesnake{ID: "c", Name: "c", Health: 100, Length: 3, Head: Coord{5, 5}, Body: []Coord{{5, 5}, {5, 5}, {5, 5}}}
+	curled := Battlesnake{ID: "d", Name: "d", Health: 90, Length: 4, Head: Coord{2, 4}, Body: []Coord{{2, 4}, {1, 4}, {1, 3}, {2, 3}}}
+	board.Snakes = []Battlesnake{mover, cornered, stacked, curled}
+
+	others := FindOtherSnakes(board.Snakes, board)
+	if len(others) != 4 {
+		t.Fatalf("expected 4 snakes, got %d", len(others))
+	}
+
+	tests := []struct {
+		name      string
+		got       OtherSnake
+		prevMove  Movement
+		moved     bool
+		tail      Coord
+		headless  []Coord
+		nextMoves []Coord
+		obstacle  bool
+		border    bool
+		corner    bool
+	}{
+		{
+			name:      "moving up in the open",
+			got:       others[0],
+			prevMove:  Up,
+			moved:     true,
+			tail:      Coord{3, 1},
+			headless:  []Coord{{3, 2}, {3, 1}},
+			nextMoves: []Coord{{3, 4}, {4, 3}, {2, 3}},
+		},
+		{
+			name:      "in a hazard corner",
+			got:       others[1],
+			prevMove:  Left,
+			moved:     true,
+			tail:      Coord{2, 0},
+			headless:  []Coord{{1, 0}, {2, 0}},
+			nextMoves: []Coord{{0, 1}},
+			obstacle:  true,
+			border:    true,
+			corner:    true,
+		},
+		{
+			name:      "stacked at the start of a game",
+			got:       others[2],
+			prevMove:  Up,
+			moved:     false,
+			tail:      Coord{5, 5},
+			headless:  []Coord{{5, 5}, {5, 5}},
+			nextMoves: []Coord{{5, 6}, {6, 5}, {5, 4}, {4, 5}},
+		},
+		{
+			name:      "tail counts as free next turn",
+			got:       others[3],
+			prevMove:  Right,
+			moved:     true,
+			tail:      Coord{2, 3},
+			headless:  []Coord{{1, 4}, {1, 3}, {2, 3}},
+			nextMoves: []Coord{{2, 5}, {3, 4}, {2, 3}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got.PrevMove != tt.prevMove {
+				t.Errorf("PrevMove = %s, want %s", tt.got.PrevMove.asString(), tt.prevMove.asString())
+			}
+			if tt.got.HasMoved() != tt.moved {
+				t.Errorf("HasMoved = %v, want %v", tt.got.HasMoved(), tt.moved)
+			}
+			if tt.got.Tail != tt.tail {
+				t.Errorf("Tail = %v, want %v", tt.got.Tail, tt.tail)
+			}
+			if !reflect.DeepEqual(tt.got.HeadlessBody, tt.headless) {
+				t.Errorf("HeadlessBody = %v, want %v", tt.got.HeadlessBody, tt.headless)
+			}
+			if !sameCoords(tt.got.NextMoves, tt.nextMoves) {
+				t.Errorf("NextMoves = %v, want %v", tt.got.NextMoves, tt.nextMoves)
+			}
+			if tt.got.IsOnObstacle != tt.obstacle || tt.got.IsOnBorder != tt.border || tt.got.IsInCorner != tt.corner {
+				t.Errorf("obstacle/border/corner = %v/%v/%v, want %v/%v/%v",
+					tt.got.IsOnObstacle, tt.got.IsOnBorder, tt.got.IsInCorner, tt.obstacle, tt.border, tt.corner)
+			}
+		})
+	}
+}
+
+func sameCoords(a, b []Coord) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, c := range a {
+		if !hasCoord(c, b) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFindFood(t *testing.T) {
+	you := Battlesnake{ID: "you", Name: "you", Health: 50, Length: 4, Head: Coord{1, 1}, Body: []Coord{{1, 1}, {1, 0}, {0, 0}, {0, 1}}}
+	equal := Battlesnake{ID: "equal", Name: "equal", Health: 50, Length: 4, Head: Coord{5, 1}, Body: []Coord{{5, 1}, {6, 1}, {6, 0}, {5, 0}}}
+	small := Battlesnake{ID: "small", Name: "small", Health: 50, Length: 3, Head: Coord{1, 5}, Body: []Coord{{1, 5}, {1, 6}, {0, 6}}}
+	board := Board{
+		Width:   7,
+		Height:  7,
+		Food:    []Coord{{3, 1}, {1, 3}, {2, 1}, {5, 3}},
+		Hazards: []Coord{{5, 3}},
+		Snakes:  []Battlesnake{you, equal, small},
+	}
+
+	food := FindFood(you, []Battlesnake{equal, small}, board, 14)
+	races := make(map[Coord]GameFood)
+	for _, f := range food {
+		races[f.Location] = f
+	}
+
+	tests := []struct {
+		location Coord
+		race     FoodRace
+		toMe     int
+		toSnake  int
+		rival    string
+	}{
+		{Coord{2, 1}, RaceWin, 1, 3, "equal"},
+		{Coord{3, 1}, RaceTieLose, 2, 2, "equal"},
+		{Coord{1, 3}, RaceTieWin, 2, 2, "small"},
+		{Coord{5, 3}, RaceLose, 6, 2, "equal"},
+	}
+	for _, tt := range tests {
+		got := races[tt.location]
+		if got.Race != tt.race || got.DistToMe != tt.toMe || got.DistToSnake != tt.toSnake || got.NearestSnakeName != tt.rival {
+			t.Errorf("food at %v = race %d, %d to me, %d to %s; want race %d, %d to me, %d to %s",
+				tt.location, got.Race, got.DistToMe, got.DistToSnake, got.NearestSnakeName, tt.race, tt.toMe, tt.toSnake, tt.rival)
+		}
+	}
+	if !races[Coord{5, 3}].IsOnObstacle {
+		t.Errorf("food at (5,3) should be in a hazard")
+	}
+	if food[0].Location != (Coord{2, 1}) {
+		t.Errorf("closest food should come first, got %v", food[0].Location)
+	}
+}
+
+func TestRulesAvoidHeadsAsLongAsUs(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	// up is next to the smaller C, but also next to B who is as long as us
+	board, err := ParseASCIIBoard(`
+		bbB....
+		...Cc..
+		aaA....
+		.......
+		.......
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	you, _ := findSnake("A", board.Snakes)
+	state := GameState{Game: Game{ID: "rules"}, Turn: 10, Board: board, You: you}
+
+	for _, m := range FindNextMoves(you, opponents(state), board.Food, board, 0, headZonesFor(state, 1)) {
+		if m.Dir == Up && (!m.IsNearSmallerSnake || !m.IsNearBiggerSnake) {
+			t.Errorf("up should be near both a smaller and a bigger head, %+v", m)
+		}
+	}
+	response := moveByRules(state)
+	if response.Move == "up" {
+		t.Errorf("moved next to a head as long as ours")
+	}
+	for _, c := range response.Trace.Candidates {
+		if c.Move == "up" && c.Dropped != moveRules[0].name {
+			t.Errorf("up dropped for %q, want %q", c.Dropped, moveRules[0].name)
+		}
+	}
+}
+
+func TestRulesLastResortStaysOnTheBoard(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	// cornered on 1 health, the hazard below is the only move left on the board
+	board, err := ParseASCIIBoard(`
+		Aa.
+		#a.
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	board.Snakes[0].Health = 1
+	you := board.Snakes[0]
+	state := GameState{Game: Game{ID: "rules"}, Turn: 10, Board: board, You: you}
+	state.Game.Ruleset.Settings.HazardDamagePerTurn = 14
+
+	response := moveByRules(state)
+	if response.Move != "down" || response.Trace.DecidedBy != "no moves left by the rules" {
+		t.Errorf("moved %s decided by %q, want down into the hazard", response.Move, response.Trace.DecidedBy)
+	}
+}
+
+func TestHealthCost(t *testing.T) {
+	board := Board{Width: 5, Height: 5, Food: []Coord{{1, 0}}, Hazards: []Coord{{0, 0}, {2, 0}, {2, 0}}}
+	path := []Coord{{0, 0}, {1, 0}, {2, 0}}
+	tests := []struct {
+		health int
+		cost   int
+		alive  bool
+	}{
+		// eating tops us up to full, the stacked hazard after it is taken from there
+		{20, 20 - (maxHealth - 1 - 2*14), true},
+		{15, 15 - (maxHealth - 1 - 2*14), false},
+	}
+	for _, tt := range tests {
+		cost, alive := healthCost(path, board, tt.health, 14)
+		if cost != tt.cost || alive != tt.alive {
+			t.Errorf("health %d cost %d alive %v, want %d %v", tt.health, cost, alive, tt.cost, tt.alive)
+		}
+	}
+}
+
+func TestHeadZoneTurnsAndOutcomes(t *testing.T) {
+	// B is as long as us until it eats the food in front of it
+	board, err := ParseASCIIBoard(`
+		.......
+		.......
+		..*Bbb.
+		.......
+		Aaa....
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	you, _ := findSnake("A", board.Snakes)
+	b, _ := findSnake("B", board.Snakes)
+	zone := MakeHeadZone(FindOtherSnakes([]Battlesnake{b}, board)[0], you, board, makeTimedMap(board), 3)
+
+	cells := make(map[Coord]ZoneCell)
+	for _, c := range zone.Cells {
+		cells[c.Coord] = c
+	}
+	tests := []struct {
+		c       Coord
+		turn    int
+		outcome HeadToHead
+	}{
+		{Coord{2, 2}, 1, BothDie},
+		{Coord{3, 3}, 1, BothDie},
+		{Coord{1, 2}, 2, WeLose},
+		// reached on turn 2 both past the food and around it, the food counts
+		{Coord{2, 3}, 2, WeLose},
+		{Coord{4, 3}, 2, BothDie},
+		{Coord{0, 2}, 3, WeLose},
+		{Coord{5, 3}, 3, BothDie},
+	}
+	for _, tt := range tests {
+		got, ok := cells[tt.c]
+		if !ok || got.Turn != tt.turn || got.Outcome != tt.outcome {
+			t.Errorf("cell %v = %+v (%v), want turn %d outcome %d", tt.c, got, ok, tt.turn, tt.outcome)
+		}
+	}
+	if _, ok := cells[Coord{6, 3}]; ok {
+		t.Errorf("zone reaches past depth 3")
+	}
+	near := headZonesWithin([]HeadZone{zone}, 1)[0]
+	if len(near.Cells) != len(near.Zone) || hasCoord(Coord{1, 2}, near.Zone) || !hasCoord(Coord{2, 2}, near.Zone) {
+		t.Errorf("zone within 1 move = %v", near.Cells)
+	}
+
+	// the head can double back, so only turns of the same parity meet
+	for turn, want := range map[int]bool{0: false, 1: true, 2: false, 3: true} {
+		if _, ok := zone.Meets(Coord{3, 3}, turn); ok != want {
+			t.Errorf("meets (3,3) on turn %d = %v, want %v", turn, ok, want)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"log"
 	"math"
+	"sync"
 )
 
 type Coords []Coord
@@ -69,7 +71,7 @@ func pathToCoord(curr, dest Coord) []Coord {
 func fillMap(board Board, me Battlesnake) GameMap {
 	var gameMap GameMap = make([][]CellOccupant, board.Width)
 	for i := range gameMap {
-		gameMap[i] = make([]CellOccupant, board.Width)
+		gameMap[i] = make([]CellOccupant, board.Height)
 	}
 
 	for x := 0; x < board.Width; x++ {
@@ -107,66 +109,66 @@ func hasCoord(curr Coord, coords []Coord) bool {
 func nearestCorner(curr Coord, board Board) Coord {
 	x := 0
 	y := 0
-	middle := board.Height / 2
 
-	if x > middle {
+	if curr.X > (board.Width-1)/2 {
 		x = board.Width - 1
 	}
-	if y > middle {
+	if curr.Y > (board.Height-1)/2 {
 		y = board.Height - 1
 	}
 	return Coord{x, y}
 }
 
-func safeMoves(curr Coord, gameMap GameMap) []Coord {
+// safeMoves returns the moves from curr that aren't into a snake, stepping
+// into hazards only where we have the health to survive them
+func safeMoves(curr Coord, gameMap GameMap, board Board, health, hazardDamage int) []Coord {
+	stacks := hazardStacks(board)
 	moves := make([]Coord, 0)
 	for i := 0; i < len(directionalMoves); i++ {
 		m := directionalMoves[i]
-		if exists, occupant := cell(curr.X+m.X, curr.Y+m.Y, gameMap); exists && isSafe(*occupant) {
-			moves = append(moves, Coord{curr.X + m.X, curr.Y + m.Y})
+		next := Coord{curr.X + m.X, curr.Y + m.Y}
+		exists, occupant := cell(next.X, next.Y, gameMap)
+		if !exists {
+			continue
+		}
+		if isSafe(*occupant) || *occupant == Hazard && stepDamage(next, stacks, hazardDamage, board.Food) < health {
+			moves = append(moves, next)
 		}
 	}
 	return moves
 }
 
-func saferMoves(curr Coord, gameMap GameMap, seen []Coord, pathLength int, opponents []Battlesnake) []Coord {
+// saferMoves returns the moves from curr that lead to a path of pathLength
+// cells, counting body segments as open once they've moved on. The first move
+// stays out of the head zones given.
+func saferMoves(curr Coord, timed TimedMap, seen []Coord, pathLength int, zones []HeadZone) []Coord {
 	moves := make([]Coord, 0)
 	if hasCoord(curr, seen) {
 		return moves
 	}
-	if pathLength == 0 && isSafe(gameMap[curr.X][curr.Y]) {
+	if pathLength == 0 {
 		return []Coord{curr}
 	}
+	seen = append(seen, curr)
+	turn := len(seen)
 	for i := 0; i < len(directionalMoves); i++ {
 		m := directionalMoves[i]
-		seen = append(seen, curr)
-		if exists, occupant := cell(curr.X+m.X, curr.Y+m.Y, gameMap); exists && isSafe(*occupant) {
-			next := Coord{curr.X + m.X, curr.Y + m.Y}
-			if len(seen) <= 0 && adjecentCellHasSnakeHead(next, opponents) {
-				continue
-			}
-			if len(saferMoves(next, gameMap, seen, pathLength-1, opponents)) > 0 {
-				moves = append(moves, Coord{curr.X + m.X, curr.Y + m.Y})
-			}
+		next := Coord{curr.X + m.X, curr.Y + m.Y}
+		if !timed.freeAt(next, turn) {
+			continue
 		}
-	}
-	return moves
-}
-
-func adjecentCellHasSnakeHead(curr Coord, snakes []Battlesnake) bool {
-	for i := 0; i < len(snakes); i++ {
-		snake := snakes[i]
-		for d := 0; d < len(directionalMoves); d++ {
-			adj := directionalMoves[d]
-			next := Coord{curr.X + adj.X, curr.Y + adj.Y}
-			if next == snake.Head {
-				return true
-			}
+		if turn == 1 && isNearSnakeHead(next, zones) {
+			continue
+		}
+		if len(saferMoves(next, timed, seen, pathLength-1, zones)) > 0 {
+			moves = append(moves, next)
 		}
 	}
-	return false
+	return moves
 }
 
+// isSafe keeps out of snakes and hazards, callers that know our health can
+// weigh hazards themselves
 func isSafe(cell CellOccupant) bool {
 	if cell == Hazard || cell == Snake {
 		return false
@@ -246,7 +248,7 @@ func abs(num int) int {
 }
 
 func (coords Coords) has(c Coord) bool {
-	for i := 0; i > len(coords); i++ {
+	for i := 0; i < len(coords); i++ {
 		if coords[i] == c {
 			return true
 		}
@@ -254,21 +256,100 @@ func (coords Coords) has(c Coord) bool {
 	return false
 }
 
-func floodFill(curr, target Coord, board Board, snakes []Battlesnake) Coords {
-	seen := make([]Coord, 0)
-	q := Queue{}
+// TimedMap holds the number of turns until each cell is clear of snake
+// bodies, 0 is clear now. Body segment i of a snake leaves after length - i turns.
+type TimedMap [][]int
+
+func makeTimedMap(board Board) TimedMap {
+	timed := make(TimedMap, board.Width)
+	for x := range timed {
+		timed[x] = make([]int, board.Height)
+	}
+	for _, s := range board.Snakes {
+		for i, c := range s.Body {
+			if isOffBoard(c, board) {
+				continue
+			}
+			// stacked segments at the start of a game take the longest
+			if turns := len(s.Body) - i; turns > timed[c.X][c.Y] {
+				timed[c.X][c.Y] = turns
+			}
+		}
+	}
+	return timed
+}
+
+// freeAt is true if c is on the board and clear of bodies once turn turns have passed
+func (timed TimedMap) freeAt(c Coord, turn int) bool {
+	if c.X < 0 || c.X >= len(timed) || c.Y < 0 || len(timed) == 0 || c.Y >= len(timed[0]) {
+		return false
+	}
+	return timed[c.X][c.Y] <= turn
+}
 
-	q.Enqueue(curr)
+// reachableArea returns the cells we can reach from start within depthLimit
+// moves (no limit when depthLimit < 0), including cells a tail will have left
+// by the time we arrive
+func reachableArea(start Coord, board Board, depthLimit int) Coords {
+	timed := makeTimedMap(board)
+	reached := make(Coords, 0)
+	depths := map[Coord]int{start: 0}
+	q := Queue{}
+	q.Enqueue(start)
 	for !q.IsEmpty() {
-		c, _ := q.Dequeue()
-		seen = append(seen, c)
-		for i := 0; i < len(makeNextMoves(c)); i++ {
-			// if is safe and not seen
-			// add to queue
+		curr, _ := q.Dequeue()
+		depth := depths[curr]
+		if depthLimit >= 0 && depth >= depthLimit {
+			continue
+		}
+		for _, next := range makeNextMoves(curr) {
+			if _, ok := depths[next]; ok || !timed.freeAt(next, depth+1) {
+				continue
+			}
+			depths[next] = depth + 1
+			reached = append(reached, next)
+			q.Enqueue(next)
 		}
 	}
+	return reached
+}
 
-	return seen
+// floodFill returns every cell reachable from curr
+func floodFill(curr Coord, board Board) Coords {
+	return reachableArea(curr, board, -1)
+}
+
+// applyMove moves one snake's head to next by the standard rules, leaving
+// every other snake where it is. As in stepState, hunger and hazards take its
+// health, and food it lands on tops it up, grows it and leaves the board.
+func applyMove(board Board, snakeID string, next Coord, hazardDamage int) Board {
+	moved := board
+	moved.Snakes = make([]Battlesnake, len(board.Snakes))
+	copy(moved.Snakes, board.Snakes)
+	for i, s := range moved.Snakes {
+		if s.ID != snakeID {
+			continue
+		}
+		body := make([]Coord, 0, len(s.Body)+1)
+		body = append(body, next)
+		body = append(body, s.Body[:len(s.Body)-1]...)
+		s.Health -= stepDamage(next, hazardStacks(board), hazardDamage, board.Food)
+		if hasFood(next, board.Food) {
+			s.Health = maxHealth
+			body = append(body, body[len(body)-1])
+			moved.Food = make([]Coord, 0, len(board.Food))
+			for _, f := range board.Food {
+				if f != next {
+					moved.Food = append(moved.Food, f)
+				}
+			}
+		}
+		s.Body = body
+		s.Head = next
+		s.Length = len(body)
+		moved.Snakes[i] = s
+	}
+	return moved
 }
 
 func hasSnakeCollision(curr Coord, snakes []Battlesnake) bool {
@@ -315,3 +396,128 @@ func UseMovement(source, target Coord) Movement {
 	}
 	return Down
 }
+
+type tailChaseModes struct {
+	mu      sync.Mutex
+	chasing map[string]bool
+}
+
+var tailChasing = tailChaseModes{chasing: make(map[string]bool)}
+
+// tailChasePlan picks the move that keeps a path back to our tail with the
+// most room around it, and returns that path. Each move's room is scored on the trace.
+func tailChasePlan(state GameState, zones []HeadZone, trace *Trace) (Coord, Path, bool) {
+	you := state.You
+	if len(you.Body) < 2 {
+		return Coord{}, Path{}, false
+	}
+
+	timed := makeTimedMap(state.Board)
+	zones = headZonesWithin(zones, 1)
+
+	var best Coord
+	var bestLoop Path
+	bestArea := -1
+	for _, next := range makeNextMoves(you.Head) {
+		move := dir(you.Head, next)
+		if !timed.freeAt(next, 1) || isNearBiggerSnakeHead(next, you, zones) {
+			trace.Drop(move, "blocked or near a bigger head")
+			continue
+		}
+		board := applyMove(state.Board, you.ID, next, state.Game.Ruleset.Settings.HazardDamagePerTurn)
+		moved, _ := findSnake(you.ID, board.Snakes)
+		tail := moved.Body[len(moved.Body)-1]
+		movedTimed := makeTimedMap(board)
+		loop, ok := FindPath(next, tail, board, func(c Coord, turn int) (int, bool) {
+			return 0, c == tail || movedTimed.freeAt(c, turn)
+		})
+		if !ok {
+			trace.Drop(move, "no way back to our tail")
+			continue
+		}
+		area := len(reachableArea(next, board, -1))
+		trace.Score(move, float64(area), map[string]float64{"area": float64(area), "loop": float64(loop.Len())})
+		if area > bestArea {
+			best, bestLoop, bestArea = next, loop, area
+		}
+	}
+	return best, bestLoop, bestArea >= 0
+}
+
+// loopThreatened is true when an opponent's head could get onto our loop
+// within tailChaseZoneDepth moves
+func loopThreatened(loop Path, zones []HeadZone) bool {
+	zones = headZonesWithin(zones, tailChaseZoneDepth)
+	for _, c := range loop.Coords {
+		if isNearSnakeHead(c, zones) {
+			return true
+		}
+	}
+	return false
+}
+
+// spaceIsTight is true when we can reach fewer than factor cells per body segment
+func spaceIsTight(state GameState, factor int) bool {
+	room := reachableWithHealth(state.You.Head, state.Board, state.You.Health, state.Game.Ruleset.Settings.HazardDamagePerTurn)
+	return len(room) < factor*state.You.Length
+}
+
+// how many moves out the tail chase watches opponent heads
+const tailChaseZoneDepth = 2
+
+// chaseTail decides whether we're in (or should switch into) tail chasing
+// mode this turn, and returns the move if so. zones must reach at least
+// tailChaseZoneDepth moves out. The plan is traced if trace isn't nil.
+func chaseTail(state GameState, p Params, zones []HeadZone, trace *Trace) (Coord, bool) {
+	key := gameKey(state)
+	tailChasing.mu.Lock()
+	chasing := tailChasing.chasing[key]
+	tailChasing.mu.Unlock()
+
+	if !chasing && state.You.Health >= p.TailChaseEnterHealth && spaceIsTight(state, p.TailChaseSpaceFactor) {
+		log.Printf("[%s] Space is tight, chasing tail", state.You.Name)
+		chasing = true
+	}
+	if !chasing {
+		return Coord{}, false
+	}
+
+	next, loop, ok := tailChasePlan(state, zones, trace)
+	switch {
+	case state.You.Health <= p.TailChaseExitHealth:
+		log.Printf("[%s] Too hungry to keep chasing tail", state.You.Name)
+		ok = false
+	case !ok:
+		log.Printf("[%s] Lost the path to our tail", state.You.Name)
+	case loopThreatened(loop, zones):
+		log.Printf("[%s] Opponent threatening our loop, stop chasing tail", state.You.Name)
+		ok = false
+	}
+	tailChasing.mu.Lock()
+	tailChasing.chasing[key] = ok
+	tailChasing.mu.Unlock()
+	return next, ok
+}
+
+func forgetTailChase(state GameState) {
+	tailChasing.mu.Lock()
+	defer tailChasing.mu.Unlock()
+	delete(tailChasing.chasing, gameKey(state))
+}
+
+// moveTailChaser settles into circling after its own tail as soon as space
+// gets tight, before looking for food, otherwise plays like moveSmart
+func moveTailChaser(state GameState) BattlesnakeMoveResponse {
+	return moveTailChaserWith(state, defaultParams)
+}
+
+// moveTailChaserWith is moveTailChaser playing by the given thresholds
+func moveTailChaserWith(state GameState, p Params) BattlesnakeMoveResponse {
+	trace := newTrace()
+	next, ok := chaseTail(state, p, headZonesFor(state, tailChaseZoneDepth), trace)
+	trace.Phase("tail chase plan")
+	if ok {
+		return trace.Respond(BattlesnakeMoveResponse{Move: dir(state.You.Head, next), Shout: "Round and round"}, "tail chase")
+	}
+	return moveSmartWith(state, p)
+}
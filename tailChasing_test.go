@@ -0,0 +1,190 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+)
+
+// boxed in, there are 24 cells to reach with a body of 9
+const boxedInBoard = `
+	.....
+	.Aaaa
+	....a
+	.aaaa
+	.....
+`
+
+func tailChaseState(t *testing.T, ascii string, health int) GameState {
+	board, err := ParseASCIIBoard(ascii)
+	if err != nil {
+		t.Fatal(err)
+	}
+	you, _ := findSnake("A", board.Snakes)
+	you.Health = health
+	for i := range board.Snakes {
+		if board.Snakes[i].ID == you.ID {
+			board.Snakes[i] = you
+		}
+	}
+	return GameState{Game: Game{ID: t.Name()}, Turn: 150, Board: board, You: you}
+}
+
+func isChasing(state GameState) bool {
+	tailChasing.mu.Lock()
+	defer tailChasing.mu.Unlock()
+	return tailChasing.chasing[gameKey(state)]
+}
+
+func TestChaseTail(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	p := defaultParams
+
+	open := tailChaseState(t, `
+		.........
+		.........
+		...Aaa...
+		.........
+		.........
+	`, 90)
+	defer forgetTailChase(open)
+	if _, ok := chaseTail(open, p, headZonesFor(open, tailChaseZoneDepth), nil); ok || isChasing(open) {
+		t.Errorf("chased our tail with room to spare")
+	}
+
+	state := tailChaseState(t, boxedInBoard, p.TailChaseEnterHealth-10)
+	defer forgetTailChase(state)
+	if _, ok := chaseTail(state, p, headZonesFor(state, tailChaseZoneDepth), nil); ok {
+		t.Errorf("started chasing our tail below %d health", p.TailChaseEnterHealth)
+	}
+
+	// enter
+	state.You.Health = p.TailChaseEnterHealth
+	if _, ok := chaseTail(state, p, headZonesFor(state, tailChaseZoneDepth), nil); !ok || !isChasing(state) {
+		t.Fatalf("didn't start chasing our tail when boxed in")
+	}
+	// stay in below the entry health
+	state.You.Health = p.TailChaseExitHealth + 1
+	if _, ok := chaseTail(state, p, headZonesFor(state, tailChaseZoneDepth), nil); !ok || !isChasing(state) {
+		t.Errorf("stopped chasing our tail above %d health", p.TailChaseExitHealth)
+	}
+	// leave when hungry
+	state.You.Health = p.TailChaseExitHealth
+	if _, ok := chaseTail(state, p, headZonesFor(state, tailChaseZoneDepth), nil); ok || isChasing(state) {
+		t.Errorf("kept chasing our tail on %d health", p.TailChaseExitHealth)
+	}
+}
+
+func TestChaseTailDropsThreatenedLoop(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	p := defaultParams
+
+	// B can get next to our tail, where the loop ends
+	state := tailChaseState(t, `
+		.....
+		.Aaaa
+		....a
+		.aaaa
+		Bb...
+	`, 90)
+	defer forgetTailChase(state)
+	zones := headZonesFor(state, tailChaseZoneDepth)
+	if _, loop, ok := tailChasePlan(state, zones, nil); !ok || !loopThreatened(loop, zones) {
+		t.Fatalf("B should threaten a loop we can make")
+	}
+	tailChasing.mu.Lock()
+	tailChasing.chasing[gameKey(state)] = true
+	tailChasing.mu.Unlock()
+
+	if _, ok := chaseTail(state, p, headZonesFor(state, tailChaseZoneDepth), nil); ok || isChasing(state) {
+		t.Errorf("kept chasing our tail with B threatening the loop")
+	}
+	if moveTailChaser(state).Trace.DecidedBy == "tail chase" {
+		t.Errorf("the chaser circled into B's reach")
+	}
+}
+
+func TestTailChaserNeedsTightSpace(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	open := tailChaseState(t, `
+		.........
+		.........
+		...Aaa...
+		.........
+		.........
+	`, 90)
+	defer forgetTailChase(open)
+	if moveTailChaser(open).Trace.DecidedBy == "tail chase" {
+		t.Errorf("the chaser circled on an open board")
+	}
+
+	boxed := tailChaseState(t, boxedInBoard, 90)
+	defer forgetTailChase(boxed)
+	if decided := moveTailChaser(boxed).Trace.DecidedBy; decided != "tail chase" {
+		t.Errorf("the chaser decided by %q when boxed in", decided)
+	}
+}
+
+func TestSafeMovesWeighHazardsByHealth(t *testing.T) {
+	board, err := ParseASCIIBoard(`
+		...
+		#A#
+		.a.
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// doubled on the left
+	board.Hazards = append(board.Hazards, Coord{0, 1})
+	you, _ := findSnake("A", board.Snakes)
+	gameMap := fillMap(board, you)
+
+	if safe := safeMoves(you.Head, gameMap, board, 100, 14); len(safe) != 3 {
+		t.Errorf("safe moves on full health %v, want up and both hazards", safe)
+	}
+	safe := safeMoves(you.Head, gameMap, board, 20, 14)
+	if len(safe) != 2 || hasCoord(Coord{0, 1}, safe) {
+		t.Errorf("safe moves on 20 health %v, want up and the single hazard", safe)
+	}
+}
+
+func TestReachableAreaWaitsForTails(t *testing.T) {
+	// coiled up with two free cells, the rest opens up as the tail moves on
+	board, err := ParseASCIIBoard(`
+		Aaa
+		..a
+		aaa
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	you, _ := findSnake("A", board.Snakes)
+	tests := []struct {
+		depth int
+		want  int
+	}{
+		{1, 1},
+		{2, 3},
+		// (2,1) is next to us on move 3 but not gone until 4
+		{3, 4},
+		{-1, 8},
+	}
+	for _, tt := range tests {
+		if got := reachableArea(you.Head, board, tt.depth); len(got) != tt.want {
+			t.Errorf("reachable within %d moves %v, want %d cells", tt.depth, got, tt.want)
+		}
+	}
+	if got := floodFill(you.Head, board); len(got) != 8 || hasCoord(you.Head, got) {
+		t.Errorf("flood fill %v, want the 8 cells other than our head", got)
+	}
+
+	// a neck next to us is still there when we'd get to it
+	if timed := makeTimedMap(board); timed.freeAt(Coord{1, 2}, 1) || !timed.freeAt(Coord{0, 0}, 1) {
+		t.Errorf("the neck should block the first move and the tail shouldn't")
+	}
+}
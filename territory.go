@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	unclaimed = -1
+	contested = -2
+)
+
+type Territory struct {
+	SnakeID   string
+	SnakeName string
+	Cells     int
+	Food      []Coord
+}
+
+type TerritoryMap struct {
+	// index into board.Snakes of the owner of each cell, or unclaimed/contested
+	Owner       [][]int
+	Territories []Territory
+	Contested   []Coord
+}
+
+// VoronoiTerritory gives every cell to the snake whose head reaches it first.
+// Ties go to the longer snake, snakes of equal length contest the cell.
+func VoronoiTerritory(board Board) TerritoryMap {
+	owner := make([][]int, board.Width)
+	dist := make([][]int, board.Width)
+	for x := range owner {
+		owner[x] = make([]int, board.Height)
+		dist[x] = make([]int, board.Height)
+		for y := range owner[x] {
+			owner[x][y] = unclaimed
+			dist[x][y] = -1
+		}
+	}
+
+	timed := makeTimedMap(board)
+
+	frontier := make([]Coord, 0, len(board.Snakes))
+	for i, s := range board.Snakes {
+		if isOffBoard(s.Head, board) {
+			continue
+		}
+		owner[s.Head.X][s.Head.Y] = i
+		dist[s.Head.X][s.Head.Y] = 0
+		frontier = append(frontier, s.Head)
+	}
+
+	for depth := 1; len(frontier) > 0; depth++ {
+		next := make([]Coord, 0)
+		for _, curr := range frontier {
+			o := owner[curr.X][curr.Y]
+			if o == contested {
+				continue
+			}
+			for _, n := range makeNextMoves(curr) {
+				if !timed.freeAt(n, depth) {
+					continue
+				}
+				switch {
+				case dist[n.X][n.Y] == -1:
+					dist[n.X][n.Y] = depth
+					owner[n.X][n.Y] = o
+					next = append(next, n)
+				case dist[n.X][n.Y] == depth && owner[n.X][n.Y] != o:
+					owner[n.X][n.Y] = resolveClaim(owner[n.X][n.Y], o, claimLength(n, depth, dist, owner, board), board)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	territory := TerritoryMap{Owner: owner, Territories: make([]Territory, len(board.Snakes))}
+	for i, s := range board.Snakes {
+		territory.Territories[i] = Territory{SnakeID: s.ID, SnakeName: s.Name, Food: make([]Coord, 0)}
+	}
+	for x := 0; x < board.Width; x++ {
+		for y := 0; y < board.Height; y++ {
+			c := Coord{x, y}
+			switch o := owner[x][y]; o {
+			case unclaimed:
+			case contested:
+				territory.Contested = append(territory.Contested, c)
+			default:
+				territory.Territories[o].Cells++
+				if hasFood(c, board.Food) {
+					territory.Territories[o].Food = append(territory.Territories[o].Food, c)
+				}
+			}
+		}
+	}
+	return territory
+}
+
+// claimLength is the length of the longest snake already claiming a contested cell
+func claimLength(c Coord, depth int, dist, owner [][]int, board Board) int {
+	if o := owner[c.X][c.Y]; o >= 0 {
+		return board.Snakes[o].Length
+	}
+	longest := 0
+	for _, n := range makeNextMoves(c) {
+		if isOffBoard(n, board) || dist[n.X][n.Y] != depth-1 {
+			continue
+		}
+		if o := owner[n.X][n.Y]; o >= 0 && board.Snakes[o].Length > longest {
+			longest = board.Snakes[o].Length
+		}
+	}
+	return longest
+}
+
+func resolveClaim(current, challenger, currentLength int, board Board) int {
+	length := board.Snakes[challenger].Length
+	if length > currentLength {
+		return challenger
+	}
+	if length == currentLength {
+		return contested
+	}
+	return current
+}
+
+// Of returns the territory of the snake with the given ID
+func (t TerritoryMap) Of(snakeID string) Territory {
+	for _, territory := range t.Territories {
+		if territory.SnakeID == snakeID {
+			return territory
+		}
+	}
+	return Territory{SnakeID: snakeID}
+}
+
+func (t TerritoryMap) String() string {
+	parts := make([]string, 0, len(t.Territories)+1)
+	for _, territory := range t.Territories {
+		parts = append(parts, fmt.Sprintf("%s: %d cells, %d food", territory.SnakeName, territory.Cells, len(territory.Food)))
+	}
+	parts = append(parts, fmt.Sprintf("contested: %d", len(t.Contested)))
+	return strings.Join(parts, ", ")
+}
+
+// share of the board we control after the move
+func territoryFeature(state GameState, move WeightedMovement) float64 {
+	cells := state.Board.Width * state.Board.Height
+	if cells == 0 {
+		return 0
+	}
+	board := applyMove(state.Board, state.You.ID, move.root, state.Game.Ruleset.Settings.HazardDamagePerTurn)
+	return float64(VoronoiTerritory(board).Of(state.You.ID).Cells) / float64(cells)
+}
@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestVoronoiTerritory(t *testing.T) {
+	tests := []struct {
+		name      string
+		ascii     string
+		a, b      int
+		contested []Coord
+		aFood     int
+	}{
+		// equal snakes split the row, the middle is contested
+		{"even", `
+			A...B
+		`, 2, 2, []Coord{{2, 0}}, 0},
+		// the longer snake takes the cells both reach at once
+		{"longer wins ties", `
+			A...B
+			a*...
+		`, 6, 4, nil, 1},
+		// bodies block until they've moved on, B is first to its own as it frees up
+		{"body moving on", `
+			A.bB.
+			..b..
+			..b..
+		`, 6, 9, nil, 0},
+	}
+	for _, tt := range tests {
+		board, err := ParseASCIIBoard(tt.ascii)
+		if err != nil {
+			t.Fatal(err)
+		}
+		territory := VoronoiTerritory(board)
+		a, b := territory.Of("A"), territory.Of("B")
+		if a.Cells != tt.a || b.Cells != tt.b {
+			t.Errorf("%s: A has %d cells, B %d, want %d and %d", tt.name, a.Cells, b.Cells, tt.a, tt.b)
+		}
+		if !sameCoords(territory.Contested, tt.contested) || len(territory.Contested) != len(tt.contested) {
+			t.Errorf("%s: contested %v, want %v", tt.name, territory.Contested, tt.contested)
+		}
+		if len(a.Food) != tt.aFood {
+			t.Errorf("%s: A's food %v, want %d", tt.name, a.Food, tt.aFood)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// TRACE_HEADER=1 sends each move's trace back in the X-Move-Trace response header
+var traceHeaderEnabled = os.Getenv("TRACE_HEADER") == "1" || os.Getenv("TRACE_HEADER") == "true"
+
+const traceHeader = "X-Move-Trace"
+
+// Trace explains how a mover picked its move. A nil trace records nothing,
+// so movers can trace unconditionally.
+type Trace struct {
+	Move       string           `json:"move"`
+	DecidedBy  string           `json:"decidedBy"`
+	Candidates []TraceCandidate `json:"candidates"`
+	Phases     []TracePhase     `json:"phases"`
+	mark       time.Time
+}
+
+// TraceCandidate is one direction we could have gone
+type TraceCandidate struct {
+	Move string `json:"move"`
+	// cells the search could reach after the move
+	Open int `json:"open,omitempty"`
+	// the search ran out of time before finishing the move
+	Partial bool `json:"partial,omitempty"`
+	// why the move was ruled out, empty if it wasn't
+	Dropped string             `json:"dropped,omitempty"`
+	Score   float64            `json:"score,omitempty"`
+	Scores  map[string]float64 `json:"scores,omitempty"`
+}
+
+// TracePhase is how long part of the decision took
+type TracePhase struct {
+	Name   string `json:"name"`
+	Micros int64  `json:"us"`
+}
+
+func newTrace() *Trace {
+	return &Trace{Candidates: make([]TraceCandidate, 0, 4), Phases: make([]TracePhase, 0), mark: time.Now()}
+}
+
+// Phase records the time since the last phase ended
+func (t *Trace) Phase(name string) {
+	if t == nil {
+		return
+	}
+	now := time.Now()
+	t.Phases = append(t.Phases, TracePhase{Name: name, Micros: now.Sub(t.mark).Microseconds()})
+	t.mark = now
+}
+
+// Consider adds the searched moves as candidates
+func (t *Trace) Consider(moves WeightedMovementSet) {
+	if t == nil {
+		return
+	}
+	for _, m := range moves {
+		c := t.candidate(m.movement.asString())
+		c.Open = len(m.open)
+		c.Partial = m.partial
+	}
+}
+
+// Keep marks every candidate missing from kept as dropped for the reason
+func (t *Trace) Keep(kept WeightedMovementSet, reason string) {
+	if t == nil {
+		return
+	}
+	for i := range t.Candidates {
+		c := &t.Candidates[i]
+		if len(c.Dropped) > 0 {
+			continue
+		}
+		found := false
+		for _, m := range kept {
+			if m.movement.asString() == c.Move {
+				found = true
+			}
+		}
+		if !found {
+			c.Dropped = reason
+		}
+	}
+}
+
+// Add lists a move as a candidate
+func (t *Trace) Add(move string) {
+	if t == nil {
+		return
+	}
+	t.candidate(move)
+}
+
+// Drop marks a single candidate as ruled out
+func (t *Trace) Drop(move, reason string) {
+	if t == nil {
+		return
+	}
+	if c := t.candidate(move); len(c.Dropped) == 0 {
+		c.Dropped = reason
+	}
+}
+
+// Score records how a candidate scored
+func (t *Trace) Score(move string, score float64, scores map[string]float64) {
+	if t == nil {
+		return
+	}
+	c := t.candidate(move)
+	c.Score = score
+	c.Scores = scores
+}
+
+// Respond finishes the trace with what decided the move and attaches it to the response
+func (t *Trace) Respond(response BattlesnakeMoveResponse, decidedBy string) BattlesnakeMoveResponse {
+	if t == nil {
+		return response
+	}
+	t.Phase("decide")
+	t.Move = response.Move
+	t.DecidedBy = decidedBy
+	response.Trace = t
+	return response
+}
+
+func (t *Trace) candidate(move string) *TraceCandidate {
+	for i := range t.Candidates {
+		if t.Candidates[i].Move == move {
+			return &t.Candidates[i]
+		}
+	}
+	t.Candidates = append(t.Candidates, TraceCandidate{Move: move})
+	return &t.Candidates[len(t.Candidates)-1]
+}
+
+// writeTrace logs the response's trace, and puts it in a header if enabled
+func writeTrace(w http.ResponseWriter, state GameState, response BattlesnakeMoveResponse) {
+	if response.Trace == nil {
+		return
+	}
+	data, err := json.Marshal(response.Trace)
+	if err != nil {
+		log.Printf("ERROR: Failed to encode trace, %s", err)
+		return
+	}
+	log.Printf("[%s] Trace %s", state.You.Name, data)
+	if traceHeaderEnabled {
+		w.Header().Set(traceHeader, string(data))
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"sort"
+)
+
+// tunedParams is a candidate parameter set and how well it played
+type tunedParams struct {
+	Params  Params
+	Fitness float64
+}
+
+// tune searches moveSmart's parameters with a genetic algorithm. Every candidate
+// plays the same self-play games against snakes on the default parameters, the
+// fittest survive and breed, and the best set found is written out for the
+// server to load with PARAMS_FILE.
+func tune(args []string) error {
+	flags := flag.NewFlagSet("tune", flag.ContinueOnError)
+	out := flags.String("out", "params.json", "where to write the best parameters")
+	generations := flags.Int("generations", 5, "generations to breed")
+	population := flags.Int("population", 8, "candidates per generation")
+	games := flags.Int("games", 4, "self-play games per candidate per generation")
+	snakes := flags.Int("snakes", 2, "snakes per game, the candidate and its rivals")
+	width := flags.Int("width", defaultSimConfig.Width, "board width")
+	height := flags.Int("height", defaultSimConfig.Height, "board height")
+	turns := flags.Int("turns", defaultSimConfig.MaxTurns, "longest a game may last")
+	seed := flags.Int64("seed", 1, "seed for the search and the games")
+	verbose := flags.Bool("verbose", false, "keep the snakes' move logs")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *population < 2 || *games < 1 || *snakes < 1 || *generations < 1 {
+		return fmt.Errorf("need at least 2 candidates, 1 game, 1 snake and 1 generation")
+	}
+	if !*verbose {
+		log.SetOutput(io.Discard)
+		defer log.SetOutput(os.Stderr)
+	}
+
+	config := defaultSimConfig
+	config.Width, config.Height, config.MaxTurns = *width, *height, *turns
+	rng := rand.New(rand.NewSource(*seed))
+
+	// start from the defaults and variations on them
+	candidates := make([]Params, *population)
+	candidates[0] = defaultParams
+	for i := 1; i < len(candidates); i++ {
+		candidates[i] = mutateParams(defaultParams, 1, rng)
+	}
+
+	var ranked []tunedParams
+	for g := 0; g < *generations; g++ {
+		ranked = make([]tunedParams, len(candidates))
+		for i, p := range candidates {
+			// every candidate plays the same games so they're compared fairly
+			ranked[i] = tunedParams{p, paramsFitness(p, *snakes, *games, config, *seed+int64(g*1000))}
+		}
+		sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Fitness > ranked[j].Fitness })
+		fmt.Printf("generation %d: best %.3f %+v\n", g+1, ranked[0].Fitness, ranked[0].Params)
+
+		next := []Params{ranked[0].Params, ranked[1].Params}
+		for len(next) < len(candidates) {
+			child := crossParams(tournament(ranked, rng), tournament(ranked, rng), rng)
+			next = append(next, mutateParams(child, 0.3, rng))
+		}
+		candidates = next
+	}
+
+	best := ranked[0]
+	data, err := json.MarshalIndent(best.Params, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Writing params with fitness %.3f to %s\n", best.Fitness, *out)
+	return os.WriteFile(*out, append(data, '\n'), 0644)
+}
+
+// paramsFitness plays the candidate against rivals on the default parameters.
+// A win scores 1, and outlasting the game counts for up to half as much again.
+func paramsFitness(p Params, snakes, games int, config SimConfig, seed int64) float64 {
+	total := 0.0
+	for g := 0; g < games; g++ {
+		players := []SimSnake{{"candidate", SmartMover(p)}}
+		for i := 1; i < snakes; i++ {
+			players = append(players, SimSnake{fmt.Sprintf("rival-%d", i), SmartMover(defaultParams)})
+		}
+		result := Simulate(players, config, seed+int64(g))
+		if result.Winner == "candidate" {
+			total++
+		}
+		if result.Turns > 0 {
+			total += 0.5 * float64(result.Survived("candidate")) / float64(result.Turns)
+		}
+	}
+	return total / float64(games)
+}
+
+// tournament picks the fittest of three random candidates
+func tournament(ranked []tunedParams, rng *rand.Rand) Params {
+	best := rng.Intn(len(ranked))
+	for i := 0; i < 2; i++ {
+		if c := rng.Intn(len(ranked)); c < best {
+			best = c
+		}
+	}
+	return ranked[best].Params
+}
+
+// crossParams takes each parameter from either parent
+func crossParams(a, b Params, rng *rand.Rand) Params {
+	child := a
+	from := b.genes()
+	for i, g := range child.genes() {
+		if rng.Intn(2) == 0 {
+			*g = *from[i]
+		}
+	}
+	return child
+}
+
+// mutateParams nudges each parameter with the given chance, by around a tenth of its range
+func mutateParams(p Params, rate float64, rng *rand.Rand) Params {
+	for i, g := range p.genes() {
+		if rng.Float64() >= rate {
+			continue
+		}
+		r := paramRanges[i]
+		step := rng.NormFloat64() * float64(r.Max-r.Min) / 10
+		if step > 0 && step < 1 {
+			step = 1
+		}
+		if step < 0 && step > -1 {
+			step = -1
+		}
+		*g += int(step)
+	}
+	p.clamp()
+	return p
+}
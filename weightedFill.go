@@ -1,9 +1,8 @@
 package main
 
 import (
+	"context"
 	"log"
-	"math/rand"
-	"time"
 )
 
 type Opponent struct {
@@ -12,14 +11,16 @@ type Opponent struct {
 	headCoord Coord
 }
 type WeightedMovement struct {
-	movement        Movement
-	root            Coord
-	obstacles       int
-	open            []Coord
-	heads           int
-	food            int
-	deadEnd         bool
-	certainDeath    bool
+	movement     Movement
+	root         Coord
+	obstacles    int
+	open         []Coord
+	heads        int
+	food         int
+	deadEnd      bool
+	certainDeath bool
+	// the search ran out of time before finishing this root, open is a lower bound
+	partial         bool
 	opponentInDmz   bool
 	movingToCorner  bool
 	distanceToFood  int
@@ -43,6 +44,16 @@ func (w *WeightedMovement) addOpenSpot(c Coord) {
 }
 
 func fillToDepth(start Coord, depthLimit int, board Board) WeightedMovementSet {
+	return fillToDepthWithin(context.Background(), start, depthLimit, board)
+}
+
+// fillToDepthWithin fills each root move in parallel, stopping early if ctx is done
+func fillToDepthWithin(ctx context.Context, start Coord, depthLimit int, board Board) WeightedMovementSet {
+	return fillToDepthWith(ctx, searchWorkers, start, depthLimit, board)
+}
+
+// fillToDepthWith is fillToDepthWithin searching on the given pool of workers
+func fillToDepthWith(ctx context.Context, workers chan struct{}, start Coord, depthLimit int, board Board) WeightedMovementSet {
 	movements := makeOpeningMoves(start)
 	otherSnakes := make([]Battlesnake, 0)
 	for i := 0; i < len(board.Snakes); i++ {
@@ -51,89 +62,106 @@ func fillToDepth(start Coord, depthLimit int, board Board) WeightedMovementSet {
 		}
 	}
 
-	for i := 0; i < len(movements); i++ {
-		depth := 0
-		countdownToNextDepth := 1
-		seen := make([]Coord, 0)
-		q := Queue{}
-		q.Enqueue(movements[i].root)
+	timed := makeTimedMap(board)
 
-		if isOffBoard(movements[i].root, board) || hasSnakeCollision(movements[i].root, board.Snakes) {
+	// certain death and corners are cheap, decide them before the search can time out
+	for i := 0; i < len(movements); i++ {
+		if !timed.freeAt(movements[i].root, 1) {
 			movements[i].certainDeath = true
 			log.Printf("Not moving %s to %v because of certain death, move deets %v", movements[i].movement.asString(), movements[i].root, movements[i])
 		}
 
 		// look out for corners.  might get trapped
-		corners := []Coord{{0, 0}, {0, board.Height - 1}, {board.Width - 1, 0}, {board.Width - 1, board.Width - 1}}
-		if hasCoord(movements[i].root, corners) {
+		if isCorner(movements[i].root, board) {
 			movements[i].movingToCorner = true
 		}
+	}
 
-		for !q.IsEmpty() {
-			curr, _ := q.Dequeue()
+	// a root stays partial if it never gets a worker before ctx is done
+	for i := range movements {
+		movements[i].partial = true
+	}
+	searchRootsWith(ctx, workers, len(movements), func(ctx context.Context, i int) {
+		fillMovement(ctx, start, &movements[i], depthLimit, board, timed, otherSnakes)
+	})
+	//log.Printf("After flood fill %v", movements)
+	return movements
+}
 
-			// depth bookkeeping
-			countdownToNextDepth--
-			if countdownToNextDepth == 0 {
-				depth++
-			}
+// fillMovement counts what can be reached from the move's root within
+// depthLimit moves. Body segments block only until they've moved on.
+// The movement is left partial if ctx is done before the fill finishes.
+func fillMovement(ctx context.Context, start Coord, movement *WeightedMovement, depthLimit int, board Board, timed TimedMap, otherSnakes []Battlesnake) {
+	depths := map[Coord]int{movement.root: 1}
+	q := Queue{}
+	q.Enqueue(movement.root)
+
+	for !q.IsEmpty() {
+		if ctx.Err() != nil {
+			log.Printf("Search for %s stopped early, %s", movement.movement.asString(), ctx.Err())
+			return
+		}
+		curr, _ := q.Dequeue()
+		depth := depths[curr]
 
-			if depth > depthLimit {
-				continue
-			}
+		if depth > depthLimit {
+			continue
+		}
 
-			// if move is safe
-			if isOffBoard(curr, board) {
-				continue
-			}
+		// if move is safe
+		if isOffBoard(curr, board) {
+			continue
+		}
 
-			if hasCoord(curr, seen) {
-				continue
-			}
-			seen = append(seen, curr)
-
-			for _, snake := range otherSnakes {
-				if hasCoord(curr, snake.Body) {
-					if curr == snake.Head {
-						movements[i].heads++
-						if movements[i].nearestOpponent.distance == 0 || movements[i].nearestOpponent.distance >= depth {
-							movements[i].nearestOpponent = Opponent{
-								distance:  distanceTo(start, curr),
-								length:    snake.Length,
-								headCoord: snake.Head,
-							}
-						}
-						if depth == 2 {
-							movements[i].opponentInDmz = true
-							log.Printf("Opponent located in DMZ")
-						}
+		for _, snake := range otherSnakes {
+			if curr == snake.Head {
+				movement.heads++
+				if movement.nearestOpponent.distance == 0 || movement.nearestOpponent.distance >= depth {
+					movement.nearestOpponent = Opponent{
+						distance:  distanceTo(start, curr),
+						length:    snake.Length,
+						headCoord: snake.Head,
 					}
-					movements[i].obstacles++
-					continue
 				}
-				// log.Printf("Nearest snake %v", movements[i].nearestOpponent)
+				if depth == 2 {
+					movement.opponentInDmz = true
+					log.Printf("Opponent located in DMZ")
+				}
 			}
+		}
 
-			for _, food := range board.Food {
-				if curr == food {
-					movements[i].food++
-					if movements[i].distanceToFood == 0 && movements[i].distanceToFood > depth {
-						movements[i].distanceToFood = depth
-					}
+		// still occupied when we'd get there
+		if !timed.freeAt(curr, depth) {
+			movement.obstacles++
+			continue
+		}
+
+		for _, food := range board.Food {
+			if curr == food {
+				movement.food++
+				if movement.distanceToFood == 0 && movement.distanceToFood > depth {
+					movement.distanceToFood = depth
 				}
 			}
+		}
 
-			//log.Printf("Adding open spot %v to %v", curr, movements[i].root)
-			movements[i].addOpenSpot(curr)
-			nextMoves := makeNextMoves(curr)
-			for _, next := range nextMoves {
-				countdownToNextDepth++
+		//log.Printf("Adding open spot %v to %v", curr, movement.root)
+		movement.addOpenSpot(curr)
+		nextMoves := makeNextMoves(curr)
+		for _, next := range nextMoves {
+			if _, ok := depths[next]; !ok {
+				depths[next] = depth + 1
 				q.Enqueue(next)
 			}
 		}
 	}
-	//log.Printf("After flood fill %v", movements)
-	return movements
+	movement.partial = false
+}
+
+// hasRoomFor is true unless the search finished and found fewer than length cells,
+// a partial search hasn't shown the move to be a dead end
+func (w WeightedMovement) hasRoomFor(length int) bool {
+	return w.partial || len(w.open) >= length
 }
 
 func (moves WeightedMovementSet) bestMoveForFood(you Battlesnake) WeightedMovement {
@@ -192,6 +220,21 @@ func (moves WeightedMovementSet) avoidCertainDeath() WeightedMovementSet {
 	return moves[:n]
 }
 
+// avoidHeadOn drops moves where a head can meet us next turn and kill us,
+// unless that would leave nothing
+func (moves WeightedMovementSet) avoidHeadOn(zones []HeadZone, trading bool) WeightedMovementSet {
+	safe := make(WeightedMovementSet, 0, len(moves))
+	for _, m := range moves {
+		if !fatalHeadOn(m.root, 1, zones, trading) {
+			safe = append(safe, m)
+		}
+	}
+	if len(safe) == 0 {
+		return moves
+	}
+	return safe
+}
+
 func (moves WeightedMovementSet) bestMoveForRoaming(you Battlesnake) WeightedMovement {
 	//log.Printf("Roaming: Possible movements %v", moves)
 	safest := make([]WeightedMovement, 0)
@@ -200,15 +243,14 @@ func (moves WeightedMovementSet) bestMoveForRoaming(you Battlesnake) WeightedMov
 		move := moves[i]
 		opponent := move.nearestOpponent
 
-		if (opponent.distance == 0 || opponent.distance > 2) && !move.opponentInDmz && !move.movingToCorner && len(move.open) >= you.Length {
+		if (opponent.distance == 0 || opponent.distance > 2) && !move.opponentInDmz && !move.movingToCorner && move.hasRoomFor(you.Length) {
 			safest = append(safest, move)
 		}
-		if opponent.distance == 0 || opponent.distance > 1 && len(move.open) >= you.Length && (opponent.headCoord.X != you.Head.X || opponent.headCoord.Y != you.Head.Y) {
+		if opponent.distance == 0 || opponent.distance > 1 && move.hasRoomFor(you.Length) && (opponent.headCoord.X != you.Head.X || opponent.headCoord.Y != you.Head.Y) {
 			safer = append(safer, move)
 		}
 	}
 
-	rand.Seed(time.Now().Unix())
 	if len(safest) > 0 {
 		return mostOpenMoves(safest)
 	} else {
@@ -225,7 +267,7 @@ func (moves WeightedMovementSet) bestMoveForRoaming(you Battlesnake) WeightedMov
 }
 
 func isCorner(c Coord, board Board) bool {
-	corners := []Coord{{0, 0}, {0, board.Height - 1}, {board.Width - 1, 0}, {board.Width - 1, board.Width - 1}}
+	corners := []Coord{{0, 0}, {0, board.Height - 1}, {board.Width - 1, 0}, {board.Width - 1, board.Height - 1}}
 	if hasCoord(c, corners) {
 		return true
 	}
@@ -278,6 +320,16 @@ func isNearSmallerSnakeHead(c Coord, you Battlesnake, snakeZone []HeadZone) bool
 	return false
 }
 
+// isNearBiggerSnakeHead counts snakes as long as us, a tie kills us both
+func isNearBiggerSnakeHead(c Coord, you Battlesnake, snakeZone []HeadZone) bool {
+	for _, s := range snakeZone {
+		if hasCoord(c, s.Zone) && s.SnakeLength >= you.Length {
+			return true
+		}
+	}
+	return false
+}
+
 func hasFood(c Coord, food []Coord) bool {
 	for _, f := range food {
 		if c == f {
@@ -287,21 +339,27 @@ func hasFood(c Coord, food []Coord) bool {
 	return false
 }
 
+// nearestSmallerSnake returns the head of the closest snake shorter than us,
+// or c itself when there isn't one
 func nearestSmallerSnake(c Coord, you Battlesnake, snakes []Battlesnake) Coord {
-	var head Coord
+	head := c
+	closest := -1
 	for _, s := range snakes {
-		if you.Length > s.Length {
-			head = c
+		if you.Length > s.Length && (closest < 0 || distanceTo(c, s.Head) < closest) {
+			head = s.Head
+			closest = distanceTo(c, s.Head)
 		}
 	}
 
 	return head
 }
 
+// mostOpenMoves picks the move reaching the most cells, on a tie the one
+// whose search didn't finish, as it could reach more
 func mostOpenMoves(possible WeightedMovementSet) WeightedMovement {
 	move := possible[0]
 	for _, m := range possible {
-		if len(m.open) > len(move.open) {
+		if len(m.open) > len(move.open) || len(m.open) == len(move.open) && m.partial && !move.partial {
 			move = m
 		}
 	}